@@ -0,0 +1,156 @@
+// Package analysis inspects ciphertexts for structural weaknesses, like the
+// repeated blocks that give away ECB mode.
+package analysis
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/Xjs/cryptopals/statistics"
+)
+
+// ErrInvalidBlockSize is returned for block sizes that are not positive
+var ErrInvalidBlockSize = errors.New("analysis: invalid block size")
+
+// A RepeatedBlock is a block that occurs more than once in a ciphertext
+type RepeatedBlock struct {
+	Block []byte
+	// Offsets are the byte offsets of all occurrences, ascending
+	Offsets []int
+}
+
+// A Report is the result of a block repetition analysis
+type Report struct {
+	BlockSize int
+	// Blocks is the number of full blocks analysed; a trailing partial block is ignored
+	Blocks int
+	// Histogram counts how often each distinct block occurs
+	Histogram statistics.BlockHistogram
+	// Repeated lists all blocks occurring more than once, ordered by first offset
+	Repeated []RepeatedBlock
+	// Score is the fraction of blocks that repeat an earlier block
+	Score statistics.Score
+	// LikelyECB is true if any block repeats. With a 16-byte block cipher in a
+	// mode like CBC or CTR, repetitions are practically impossible.
+	LikelyECB bool
+}
+
+// AnalyzeBlocks splits ciphertext into blocks of blockSize bytes and reports on repetitions
+func AnalyzeBlocks(ciphertext []byte, blockSize int) (*Report, error) {
+	if blockSize <= 0 {
+		return nil, ErrInvalidBlockSize
+	}
+
+	r := &Report{BlockSize: blockSize, Blocks: len(ciphertext) / blockSize}
+
+	counts := make(map[string]int)
+	offsets := make(map[string][]int)
+	var order []string
+	for i := 0; i < r.Blocks; i++ {
+		offset := i * blockSize
+		block := string(ciphertext[offset : offset+blockSize])
+		if counts[block] == 0 {
+			order = append(order, block)
+		}
+		counts[block]++
+		offsets[block] = append(offsets[block], offset)
+	}
+
+	r.Histogram = statistics.NewBlockHistogram(counts)
+	for _, block := range order {
+		if counts[block] > 1 {
+			r.Repeated = append(r.Repeated, RepeatedBlock{Block: []byte(block), Offsets: offsets[block]})
+		}
+	}
+	if r.Blocks > 0 {
+		r.Score = statistics.RelativeScore(r.Blocks-len(counts), r.Blocks)
+	}
+	r.LikelyECB = len(r.Repeated) > 0
+
+	return r, nil
+}
+
+// A Decoder turns a line of text into ciphertext bytes
+type Decoder func(line string) ([]byte, error)
+
+var (
+	// HexDecoder decodes hex-encoded lines
+	HexDecoder Decoder = hex.DecodeString
+	// Base64Decoder decodes standard base64-encoded lines
+	Base64Decoder Decoder = base64.StdEncoding.DecodeString
+)
+
+// A LineReport is the analysis of a single line of input
+type LineReport struct {
+	// Line is the 1-based line number
+	Line       int
+	Ciphertext []byte
+	*Report
+}
+
+// LineReports is a list of LineReport. It sorts by descending score,
+// then by ascending line number.
+type LineReports []LineReport
+
+func (lr LineReports) Less(a, b int) bool {
+	if lr[a].Score != lr[b].Score {
+		return lr[a].Score > lr[b].Score
+	}
+	return lr[a].Line < lr[b].Line
+}
+func (lr LineReports) Swap(a, b int) { lr[a], lr[b] = lr[b], lr[a] }
+func (lr LineReports) Len() int      { return len(lr) }
+
+// ScanLines decodes every non-empty line read from r with decode and analyses
+// it with AnalyzeBlocks. The reports are returned with the most ECB-like line
+// first. Decoding errors are returned along with the line number.
+func ScanLines(r io.Reader, decode Decoder, blockSize int) (LineReports, error) {
+	if blockSize <= 0 {
+		return nil, ErrInvalidBlockSize
+	}
+
+	var result LineReports
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<24)
+	var line int
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+
+		ciphertext, err := decode(text)
+		if err != nil {
+			return nil, &LineError{Line: line, Err: err}
+		}
+
+		report, err := AnalyzeBlocks(ciphertext, blockSize)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, LineReport{Line: line, Ciphertext: ciphertext, Report: report})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.Stable(result)
+	return result, nil
+}
+
+// A LineError is returned by ScanLines if a line cannot be decoded
+type LineError struct {
+	Line int
+	Err  error
+}
+
+func (e *LineError) Error() string {
+	return fmt.Sprintf("analysis: line %d: %v", e.Line, e.Err)
+}
@@ -0,0 +1,103 @@
+package analysis
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/rand"
+	"encoding/hex"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/Xjs/cryptopals/modes"
+)
+
+func TestAnalyzeBlocks(t *testing.T) {
+	a := bytes.Repeat([]byte{'A'}, 4)
+	b := bytes.Repeat([]byte{'B'}, 4)
+	c := bytes.Repeat([]byte{'C'}, 4)
+	input := bytes.Join([][]byte{a, b, a, c, a, b, []byte("xy")}, nil)
+
+	r, err := AnalyzeBlocks(input, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Blocks != 6 {
+		t.Errorf("Blocks = %d, want 6", r.Blocks)
+	}
+	if !r.LikelyECB {
+		t.Error("LikelyECB = false")
+	}
+	if want := 0.5; float64(r.Score) != want {
+		t.Errorf("Score = %v, want %v", r.Score, want)
+	}
+	if len(r.Repeated) != 2 {
+		t.Fatalf("Repeated = %v, want 2 entries", r.Repeated)
+	}
+	if !bytes.Equal(r.Repeated[0].Block, a) || len(r.Repeated[0].Offsets) != 3 || r.Repeated[0].Offsets[2] != 16 {
+		t.Errorf("Repeated[0] = %+v", r.Repeated[0])
+	}
+	if high := r.Histogram.GetHigh(0); !bytes.Equal(high.Block, a) || high.Count != 3 {
+		t.Errorf("Histogram.GetHigh(0) = %+v", high)
+	}
+
+	if _, err := AnalyzeBlocks(input, 0); err != ErrInvalidBlockSize {
+		t.Errorf("AnalyzeBlocks() error = %v, want %v", err, ErrInvalidBlockSize)
+	}
+}
+
+func TestScanLines(t *testing.T) {
+	block, err := aes.NewCipher([]byte("YELLOW SUBMARINE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecb, err := modes.EncryptECB(block, bytes.Repeat([]byte("sixteen byte blk"), 4))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var lines []string
+	for i := 0; i < 10; i++ {
+		random := make([]byte, 64)
+		rand.Read(random)
+		lines = append(lines, hex.EncodeToString(random))
+	}
+	lines[6] = hex.EncodeToString(ecb)
+
+	reports, err := ScanLines(strings.NewReader(strings.Join(lines, "\n")), HexDecoder, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) != 10 {
+		t.Fatalf("got %d reports, want 10", len(reports))
+	}
+	if reports[0].Line != 7 || !reports[0].LikelyECB {
+		t.Errorf("best line = %d (likely ECB: %v), want 7", reports[0].Line, reports[0].LikelyECB)
+	}
+	for _, r := range reports[1:] {
+		if r.LikelyECB {
+			t.Errorf("line %d looks like ECB", r.Line)
+		}
+	}
+
+	_, err = ScanLines(strings.NewReader("00ff\nnot hex\n"), HexDecoder, 16)
+	if le, ok := err.(*LineError); !ok || le.Line != 2 {
+		t.Errorf("ScanLines() error = %v, want LineError for line 2", err)
+	}
+}
+
+func TestScanLinesSingleByteXOR(t *testing.T) {
+	f, err := os.Open("../exercises/4/data.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	reports, err := ScanLines(f, HexDecoder, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(reports) == 0 || reports[0].LikelyECB {
+		t.Errorf("single-byte XOR lines should not look like ECB")
+	}
+}
@@ -0,0 +1,101 @@
+// Package cbc implements attacks against block ciphers in CBC mode.
+package cbc
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/Xjs/cryptopals/modes"
+	"github.com/Xjs/cryptopals/sliceops"
+	"github.com/Xjs/cryptopals/xor"
+)
+
+var (
+	// ErrEditRange is returned if an edit lies outside the plaintext
+	ErrEditRange = errors.New("cbc: edit out of range")
+	// ErrEditLength is returned if the known and desired plaintexts of an edit differ in length
+	ErrEditLength = errors.New("cbc: known and desired plaintext differ in length")
+	// ErrScrambledEdit is returned if an edit touches a block that another
+	// edit scrambles, e.g. for edits spanning adjacent blocks
+	ErrScrambledEdit = errors.New("cbc: edit touches a scrambled block")
+)
+
+// An Edit replaces Known plaintext at byte Offset with Desired plaintext of the same length
+type Edit struct {
+	Offset  int
+	Known   []byte
+	Desired []byte
+}
+
+// Delta returns the XOR difference between known and desired plaintext, which
+// is what has to be XORed into the ciphertext that known depends on
+func Delta(known, desired []byte) ([]byte, error) {
+	if len(known) != len(desired) {
+		return nil, ErrEditLength
+	}
+	return xor.Encrypt(known, desired), nil
+}
+
+// A Flip is the result of Bitflip
+type Flip struct {
+	IV         []byte
+	Ciphertext []byte
+	// Scrambled lists the plaintext blocks that decrypt to garbage, ascending
+	Scrambled []int
+}
+
+// Bitflip modifies a CBC ciphertext so that it decrypts with the given edits
+// applied. An edit of plaintext block i is done by XORing its Delta into
+// ciphertext block i-1, which scrambles plaintext block i-1; edits of block 0
+// modify the IV instead, without scrambling anything. Edits may span several
+// blocks, as long as no edited block is scrambled by another edit.
+// iv and ciphertext are not modified.
+func Bitflip(iv, ciphertext []byte, blockSize int, edits ...Edit) (*Flip, error) {
+	if len(iv) != blockSize {
+		return nil, modes.ErrInvalidIV
+	}
+
+	// prefixed is IV || ciphertext, so that block i of the plaintext depends on block i here
+	prefixed := make([]byte, len(iv)+len(ciphertext))
+	copy(prefixed, iv)
+	copy(prefixed[len(iv):], ciphertext)
+
+	edited := make(map[int]bool)
+	scrambled := make(map[int]bool)
+	for _, e := range edits {
+		delta, err := Delta(e.Known, e.Desired)
+		if err != nil {
+			return nil, err
+		}
+		if e.Offset < 0 || e.Offset+len(delta) > len(ciphertext) {
+			return nil, ErrEditRange
+		}
+
+		for i := range delta {
+			block := (e.Offset + i) / blockSize
+			edited[block] = true
+			if block > 0 {
+				scrambled[block-1] = true
+			}
+		}
+
+		// prefixed is shifted by one block relative to the plaintext
+		target := prefixed[e.Offset : e.Offset+len(delta)]
+		flipped, err := sliceops.MapOperator(target, delta, sliceops.XOR)
+		if err != nil {
+			return nil, err
+		}
+		copy(target, flipped)
+	}
+
+	result := &Flip{IV: prefixed[:len(iv)], Ciphertext: prefixed[len(iv):]}
+	for block := range scrambled {
+		if edited[block] {
+			return nil, ErrScrambledEdit
+		}
+		result.Scrambled = append(result.Scrambled, block)
+	}
+	sort.Ints(result.Scrambled)
+
+	return result, nil
+}
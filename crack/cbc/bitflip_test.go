@@ -0,0 +1,138 @@
+package cbc
+
+import (
+	"bytes"
+	"crypto/aes"
+	"math/rand"
+	"testing"
+
+	"github.com/Xjs/cryptopals/modes"
+	"github.com/Xjs/cryptopals/oracle"
+)
+
+// TestBitflipChallenge16 is challenge 16
+func TestBitflipChallenge16(t *testing.T) {
+	o, err := oracle.NewComments(rand.New(rand.NewSource(16)), oracle.CBC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The prefix is exactly two blocks, so userdata starts at block 2
+	known := []byte("XadminXtrueX")
+	ciphertext, err := o.Encrypt(append(bytes.Repeat([]byte{'X'}, aes.BlockSize), known...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := o.IsAdmin(ciphertext); ok {
+		t.Fatal("oracle did not quote its input")
+	}
+
+	flip, err := Bitflip(ciphertext[:aes.BlockSize], ciphertext[aes.BlockSize:], aes.BlockSize, Edit{
+		Offset:  len(oracle.CommentsPrefix) + aes.BlockSize,
+		Known:   known,
+		Desired: []byte(";admin=true;"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(flip.Scrambled) != 1 || flip.Scrambled[0] != 2 {
+		t.Errorf("Scrambled = %v, want [2]", flip.Scrambled)
+	}
+
+	ok, err := o.IsAdmin(append(flip.IV, flip.Ciphertext...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Error("forged ciphertext is not admin")
+	}
+}
+
+func TestBitflip(t *testing.T) {
+	block, err := aes.NewCipher([]byte("YELLOW SUBMARINE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	iv := []byte("0123456789abcdef")
+	plaintext := []byte("block zero......block one.......block two.......block three.....")
+	ciphertext, err := modes.EncryptCBC(block, iv, plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		edits         []Edit
+		wantScrambled []int
+		wantErr       error
+	}{
+		{"iv", []Edit{{0, []byte("block zero"), []byte("BLOCK ZERO")}}, nil, nil},
+		{"two-blocks", []Edit{
+			{16, []byte("block one"), []byte("BLOCK ONE")},
+			{48, []byte("block three"), []byte("BLOCK THREE")},
+		}, []int{0, 2}, nil},
+		{"spanning", []Edit{{10, []byte("......block"), []byte("!!!!!!BLOCK")}}, nil, ErrScrambledEdit},
+		{"adjacent", []Edit{
+			{16, []byte("block one"), []byte("BLOCK ONE")},
+			{32, []byte("block two"), []byte("BLOCK TWO")},
+		}, nil, ErrScrambledEdit},
+		{"length", []Edit{{0, []byte("block"), []byte("BLOCK!")}}, nil, ErrEditLength},
+		{"range", []Edit{{60, []byte("three"), []byte("THREE")}}, nil, ErrEditRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flip, err := Bitflip(iv, ciphertext, aes.BlockSize, tt.edits...)
+			if err != tt.wantErr {
+				t.Fatalf("Bitflip() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if !equalInts(flip.Scrambled, tt.wantScrambled) {
+				t.Errorf("Scrambled = %v, want %v", flip.Scrambled, tt.wantScrambled)
+			}
+
+			got, err := modes.DecryptCBC(block, flip.IV, flip.Ciphertext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			want := append([]byte(nil), plaintext...)
+			for _, e := range tt.edits {
+				copy(want[e.Offset:], e.Desired)
+			}
+			for i := 0; i < len(want); i += aes.BlockSize {
+				if contains(flip.Scrambled, i/aes.BlockSize) {
+					continue
+				}
+				if !bytes.Equal(got[i:i+aes.BlockSize], want[i:i+aes.BlockSize]) {
+					t.Errorf("block %d = %q, want %q", i/aes.BlockSize, got[i:i+aes.BlockSize], want[i:i+aes.BlockSize])
+				}
+			}
+		})
+	}
+
+	if !bytes.Equal(iv, []byte("0123456789abcdef")) {
+		t.Error("Bitflip modified the IV")
+	}
+}
+
+func contains(s []int, x int) bool {
+	for _, v := range s {
+		if v == x {
+			return true
+		}
+	}
+	return false
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,53 @@
+package cbc
+
+import (
+	"errors"
+
+	"github.com/Xjs/cryptopals/oracle"
+	"github.com/Xjs/cryptopals/xor"
+)
+
+var (
+	// ErrTooFewBlocks is returned if a ciphertext is too short for an attack
+	ErrTooFewBlocks = errors.New("cbc: too few ciphertext blocks")
+	// ErrNoLeak is returned if the receiver does not reveal the plaintext
+	ErrNoLeak = errors.New("cbc: receiver did not leak plaintext")
+)
+
+// A Receiver decrypts ciphertext in CBC mode, returning an
+// *oracle.HighASCIIError containing the plaintext if it is not ASCII
+type Receiver func(ciphertext []byte) ([]byte, error)
+
+// RecoverKeyFromIVEqualsKey recovers the key of a receiver that uses it as IV
+// (challenge 27), given a ciphertext of at least three blocks. It submits
+// C1 || 0 || C1, whose plaintext blocks P1' and P3' are D(C1) XOR key and
+// D(C1), so the key is P1' XOR P3'.
+func RecoverKeyFromIVEqualsKey(receive Receiver, ciphertext []byte, blockSize int) ([]byte, error) {
+	if len(ciphertext) < 3*blockSize {
+		return nil, ErrTooFewBlocks
+	}
+
+	// Keep the rest of the ciphertext. The forged blocks garble the padding
+	// unless the ciphertext has at least five blocks; the attack only works
+	// because the receiver checks for high ASCII before it unpads.
+	forged := make([]byte, len(ciphertext))
+	copy(forged, ciphertext)
+	for i := blockSize; i < 2*blockSize; i++ {
+		forged[i] = 0
+	}
+	copy(forged[2*blockSize:], ciphertext[:blockSize])
+
+	_, err := receive(forged)
+	leak, ok := err.(*oracle.HighASCIIError)
+	if !ok {
+		if err == nil {
+			return nil, ErrNoLeak
+		}
+		return nil, err
+	}
+	if len(leak.Plaintext) < 3*blockSize {
+		return nil, ErrNoLeak
+	}
+
+	return xor.Encrypt(leak.Plaintext[:blockSize], leak.Plaintext[2*blockSize:3*blockSize]), nil
+}
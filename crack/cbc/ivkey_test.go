@@ -0,0 +1,67 @@
+package cbc
+
+import (
+	"bytes"
+	"crypto/aes"
+	"math/rand"
+	"testing"
+
+	"github.com/Xjs/cryptopals/modes"
+	"github.com/Xjs/cryptopals/oracle"
+	"github.com/Xjs/cryptopals/padding"
+)
+
+// TestRecoverKeyFromIVEqualsKey is challenge 27
+func TestRecoverKeyFromIVEqualsKey(t *testing.T) {
+	o, err := oracle.NewIVKey(rand.New(rand.NewSource(27)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte(oracle.CommentsPrefix + "an innocent user" + oracle.CommentsSuffix)
+	ciphertext, err := o.Encrypt(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, err := RecoverKeyFromIVEqualsKey(o.Decrypt, ciphertext, aes.BlockSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext, err := modes.DecryptCBC(block, key, ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext, err = padding.Unpad(plaintext, aes.BlockSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(plaintext, message) {
+		t.Errorf("decrypted %q with recovered key, want %q", plaintext, message)
+	}
+}
+
+func TestRecoverKeyFromIVEqualsKeyErrors(t *testing.T) {
+	ascii := Receiver(func(ciphertext []byte) ([]byte, error) { return ciphertext, nil })
+	tests := []struct {
+		name       string
+		receive    Receiver
+		ciphertext []byte
+		want       error
+	}{
+		{"short", ascii, make([]byte, 2*aes.BlockSize), ErrTooFewBlocks},
+		{"no-leak", ascii, make([]byte, 3*aes.BlockSize), ErrNoLeak},
+		{"truncated-leak", func([]byte) ([]byte, error) {
+			return nil, &oracle.HighASCIIError{Plaintext: []byte{0xff}}
+		}, make([]byte, 3*aes.BlockSize), ErrNoLeak},
+	}
+	for _, tt := range tests {
+		if _, err := RecoverKeyFromIVEqualsKey(tt.receive, tt.ciphertext, aes.BlockSize); err != tt.want {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package cbc
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/Xjs/cryptopals/modes"
+	"github.com/Xjs/cryptopals/padding"
+	"github.com/Xjs/cryptopals/sliceops"
+)
+
+// ErrNoValidPadding is returned if no guess for a byte yields valid padding,
+// which means the oracle does not behave like a padding oracle
+var ErrNoValidPadding = errors.New("cbc: no guess yields valid padding")
+
+// A PaddingOracle decrypts ciphertext in CBC mode with the given IV and
+// reports whether the result has valid PKCS#7 padding
+type PaddingOracle func(iv, ciphertext []byte) bool
+
+// A PaddingOracleAttack decrypts and encrypts data using a PaddingOracle
+// without knowing the key. It is safe for concurrent use if the oracle is.
+type PaddingOracleAttack struct {
+	// queries is accessed atomically; as the first field it is 64-bit aligned
+	// on 32-bit platforms as well
+	queries int64
+
+	oracle    PaddingOracle
+	blockSize int
+	// Workers is the number of concurrent oracle queries while guessing a byte.
+	// It defaults to runtime.NumCPU().
+	Workers int
+	// Random is used to pick the last ciphertext block in Encrypt. It defaults to crypto/rand.Reader.
+	Random io.Reader
+}
+
+// NewPaddingOracleAttack creates a PaddingOracleAttack for a cipher with the given block size
+func NewPaddingOracleAttack(oracle PaddingOracle, blockSize int) *PaddingOracleAttack {
+	return &PaddingOracleAttack{oracle: oracle, blockSize: blockSize}
+}
+
+// Queries returns the number of oracle queries made so far
+func (a *PaddingOracleAttack) Queries() int64 {
+	return atomic.LoadInt64(&a.queries)
+}
+
+func (a *PaddingOracleAttack) query(iv, block []byte) bool {
+	atomic.AddInt64(&a.queries, 1)
+	return a.oracle(iv, block)
+}
+
+func (a *PaddingOracleAttack) workers() int {
+	if a.Workers > 0 {
+		return a.Workers
+	}
+	return runtime.NumCPU()
+}
+
+// Intermediate recovers the block cipher decryption of a single ciphertext block,
+// i.e. the value that is XORed with the previous block to give the plaintext.
+func (a *PaddingOracleAttack) Intermediate(block []byte) ([]byte, error) {
+	if len(block) != a.blockSize {
+		return nil, modes.ErrNotFullBlocks
+	}
+
+	intermediate := make([]byte, a.blockSize)
+	for pos := a.blockSize - 1; pos >= 0; pos-- {
+		b, err := a.guess(block, intermediate, pos)
+		if err != nil {
+			return nil, err
+		}
+		intermediate[pos] = b
+	}
+	return intermediate, nil
+}
+
+// guess finds intermediate[pos], given that all intermediate bytes after pos are known.
+// It tries all 256 values of the forged IV byte at pos concurrently.
+func (a *PaddingOracleAttack) guess(block, intermediate []byte, pos int) (byte, error) {
+	pad := byte(a.blockSize - pos)
+
+	guesses := make(chan int)
+	results := make(chan byte, 256)
+	stop := make(chan struct{})
+	var once sync.Once
+	var wg sync.WaitGroup
+
+	for w := 0; w < a.workers(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for g := range guesses {
+				forged := make([]byte, a.blockSize)
+				for k := pos + 1; k < a.blockSize; k++ {
+					forged[k] = intermediate[k] ^ pad
+				}
+				forged[pos] = byte(g)
+				if !a.query(forged, block) {
+					continue
+				}
+				// For the last byte, valid padding may also be \x02\x02 or longer
+				// by accident. Changing the byte before tells the cases apart.
+				if pad == 1 && pos > 0 {
+					forged[pos-1] ^= 0xff
+					if !a.query(forged, block) {
+						continue
+					}
+				}
+				results <- byte(g) ^ pad
+				once.Do(func() { close(stop) })
+			}
+		}()
+	}
+
+feed:
+	for g := 0; g < 256; g++ {
+		select {
+		case guesses <- g:
+		case <-stop:
+			break feed
+		}
+	}
+	close(guesses)
+	wg.Wait()
+	close(results)
+
+	b, ok := <-results
+	if !ok {
+		return 0, ErrNoValidPadding
+	}
+	return b, nil
+}
+
+// Decrypt decrypts ciphertext using the padding oracle. The result still
+// carries its padding.
+func (a *PaddingOracleAttack) Decrypt(iv, ciphertext []byte) ([]byte, error) {
+	if len(iv) != a.blockSize {
+		return nil, modes.ErrInvalidIV
+	}
+	if len(ciphertext)%a.blockSize != 0 {
+		return nil, modes.ErrNotFullBlocks
+	}
+
+	var result []byte
+	previous := iv
+	for i := 0; i < len(ciphertext); i += a.blockSize {
+		block := ciphertext[i : i+a.blockSize]
+		intermediate, err := a.Intermediate(block)
+		if err != nil {
+			return nil, err
+		}
+		plaintext, err := sliceops.MapOperator(intermediate, previous, sliceops.XOR)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, plaintext...)
+		previous = block
+	}
+	return result, nil
+}
+
+// Encrypt creates an IV and ciphertext that decrypt to the PKCS#7-padded
+// plaintext under the oracle's key. Starting from a random last block, every
+// preceding block is chosen such that its XOR with the next block's
+// intermediate value yields the desired plaintext.
+func (a *PaddingOracleAttack) Encrypt(plaintext []byte) ([]byte, []byte, error) {
+	padded, err := padding.Pad(plaintext, a.blockSize)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	random := a.Random
+	if random == nil {
+		random = rand.Reader
+	}
+
+	n := len(padded) / a.blockSize
+	blocks := make([][]byte, n+1)
+	blocks[n] = make([]byte, a.blockSize)
+	if _, err := io.ReadFull(random, blocks[n]); err != nil {
+		return nil, nil, err
+	}
+
+	for i := n; i > 0; i-- {
+		intermediate, err := a.Intermediate(blocks[i])
+		if err != nil {
+			return nil, nil, err
+		}
+		blocks[i-1], err = sliceops.MapOperator(intermediate, padded[(i-1)*a.blockSize:i*a.blockSize], sliceops.XOR)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var ciphertext []byte
+	for _, b := range blocks[1:] {
+		ciphertext = append(ciphertext, b...)
+	}
+	return blocks[0], ciphertext, nil
+}
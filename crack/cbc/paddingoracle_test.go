@@ -0,0 +1,163 @@
+package cbc
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"encoding/hex"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Xjs/cryptopals/modes"
+	"github.com/Xjs/cryptopals/padding"
+)
+
+var challenge17Strings = []string{
+	"MDAwMDAwTm93IHRoYXQgdGhlIHBhcnR5IGlzIGp1bXBpbmc=",
+	"MDAwMDAxV2l0aCB0aGUgYmFzcyBraWNrZWQgaW4gYW5kIHRoZSBWZWdhJ3MgYXJlIHB1bXBpbic=",
+	"MDAwMDAyUXVpY2sgdG8gdGhlIHBvaW50LCB0byB0aGUgcG9pbnQsIG5vIGZha2luZw==",
+	"MDAwMDAzQ29va2luZyBNQydzIGxpa2UgYSBwb3VuZCBvZiBiYWNvbg==",
+	"MDAwMDA0QnVybmluZyAnZW0sIGlmIHlvdSBhaW4ndCBxdWljayBhbmQgbmltYmxl",
+	"MDAwMDA1SSBnbyBjcmF6eSB3aGVuIEkgaGVhciBhIGN5bWJhbA==",
+	"MDAwMDA2QW5kIGEgaGlnaCBoYXQgd2l0aCBhIHNvdXBlZCB1cCB0ZW1wbw==",
+	"MDAwMDA3SSdtIG9uIGEgcm9sbCwgaXQncyB0aW1lIHRvIGdvIHNvbG8=",
+	"MDAwMDA4b2xsaW4nIGluIG15IGZpdmUgcG9pbnQgb2g=",
+	"MDAwMDA5aXRoIG15IHJhZy10b3AgZG93biBzbyBteSBoYWlyIGNhbiBibG93",
+}
+
+// paddingServer is the server of challenge 17
+type paddingServer struct {
+	block cipher.Block
+}
+
+func newPaddingServer(t *testing.T, random io.Reader) *paddingServer {
+	key := make([]byte, aes.BlockSize)
+	io.ReadFull(random, key)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &paddingServer{block: block}
+}
+
+func (s *paddingServer) encrypt(t *testing.T, random io.Reader, plaintext []byte) ([]byte, []byte) {
+	iv := make([]byte, aes.BlockSize)
+	io.ReadFull(random, iv)
+	padded, err := padding.Pad(plaintext, aes.BlockSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext, err := modes.EncryptCBC(s.block, iv, padded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return iv, ciphertext
+}
+
+func (s *paddingServer) validPadding(iv, ciphertext []byte) bool {
+	plaintext, err := modes.DecryptCBC(s.block, iv, ciphertext)
+	if err != nil {
+		return false
+	}
+	_, err = padding.Unpad(plaintext, aes.BlockSize)
+	return err == nil
+}
+
+// TestPaddingOracleChallenge17 is challenge 17
+func TestPaddingOracleChallenge17(t *testing.T) {
+	random := rand.New(rand.NewSource(17))
+	s := newPaddingServer(t, random)
+	attack := NewPaddingOracleAttack(s.validPadding, aes.BlockSize)
+
+	for _, str := range challenge17Strings {
+		want, err := base64.StdEncoding.DecodeString(str)
+		if err != nil {
+			t.Fatal(err)
+		}
+		iv, ciphertext := s.encrypt(t, random, want)
+
+		got, err := attack.Decrypt(iv, ciphertext)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err = padding.Unpad(got, aes.BlockSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("Decrypt() = %q, want %q", got, want)
+		}
+	}
+	t.Logf("%d oracle queries", attack.Queries())
+}
+
+// TestPaddingOracleFalsePositive uses a plaintext ending in \x02 before the
+// padding block, which tricks naive attacks on the last byte
+func TestPaddingOracleFalsePositive(t *testing.T) {
+	random := rand.New(rand.NewSource(2))
+	s := newPaddingServer(t, random)
+	for _, workers := range []int{1, 4} {
+		attack := NewPaddingOracleAttack(s.validPadding, aes.BlockSize)
+		attack.Workers = workers
+		for _, want := range [][]byte{
+			bytes.Repeat([]byte{2}, 15),
+			append(bytes.Repeat([]byte{'A'}, 14), 3, 3),
+			bytes.Repeat([]byte{1}, 31),
+		} {
+			iv, ciphertext := s.encrypt(t, random, want)
+			got, err := attack.Decrypt(iv, ciphertext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got, _ = padding.Unpad(got, aes.BlockSize); !bytes.Equal(got, want) {
+				t.Errorf("workers %d: Decrypt() = %x, want %x", workers, got, want)
+			}
+		}
+	}
+}
+
+// TestPaddingOracleHTTP runs the attack against a padding oracle behind HTTP,
+// and uses it to encrypt a message of our choice
+func TestPaddingOracleHTTP(t *testing.T) {
+	random := rand.New(rand.NewSource(3))
+	s := newPaddingServer(t, random)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		iv, err1 := hex.DecodeString(r.URL.Query().Get("iv"))
+		ciphertext, err2 := hex.DecodeString(r.URL.Query().Get("c"))
+		if err1 != nil || err2 != nil || !s.validPadding(iv, ciphertext) {
+			w.WriteHeader(http.StatusForbidden)
+		}
+	}))
+	defer server.Close()
+
+	oracle := func(iv, ciphertext []byte) bool {
+		resp, err := http.Get(server.URL + "?iv=" + hex.EncodeToString(iv) + "&c=" + hex.EncodeToString(ciphertext))
+		if err != nil {
+			t.Error(err)
+			return false
+		}
+		resp.Body.Close()
+		return resp.StatusCode == http.StatusOK
+	}
+	attack := NewPaddingOracleAttack(oracle, aes.BlockSize)
+	attack.Workers = 8
+	attack.Random = random
+
+	want := []byte("Chosen plaintext, encrypted without the key")
+	iv, ciphertext, err := attack.Encrypt(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := modes.DecryptCBC(s.block, iv, ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ = padding.Unpad(got, aes.BlockSize); !bytes.Equal(got, want) {
+		t.Errorf("Encrypt() gave ciphertext for %q, want %q", got, want)
+	}
+	t.Logf("%d oracle queries", attack.Queries())
+}
@@ -0,0 +1,43 @@
+package ctr
+
+import (
+	"github.com/Xjs/cryptopals/crack/cbc"
+	"github.com/Xjs/cryptopals/oracle"
+	"github.com/Xjs/cryptopals/sliceops"
+)
+
+// RecoverWithEdit recovers the plaintext of a CTR ciphertext from an oracle
+// that lets an attacker edit it (challenge 25). Since the edit XORs newtext
+// with the key stream, editing the ciphertext with itself as new text yields
+// the plaintext in a single query.
+func RecoverWithEdit(e oracle.Editor, ciphertext []byte) ([]byte, error) {
+	return e.Edit(ciphertext, 0, ciphertext)
+}
+
+// Bitflip modifies a CTR ciphertext so that it decrypts with the given edits
+// applied (challenge 26). Unlike in CBC, the Delta of an edit is XORed into
+// the ciphertext at the edited position itself, and nothing is scrambled.
+// ciphertext is not modified.
+func Bitflip(ciphertext []byte, edits ...cbc.Edit) ([]byte, error) {
+	result := make([]byte, len(ciphertext))
+	copy(result, ciphertext)
+
+	for _, e := range edits {
+		delta, err := cbc.Delta(e.Known, e.Desired)
+		if err != nil {
+			return nil, err
+		}
+		if e.Offset < 0 || e.Offset+len(delta) > len(result) {
+			return nil, cbc.ErrEditRange
+		}
+
+		target := result[e.Offset : e.Offset+len(delta)]
+		flipped, err := sliceops.MapOperator(target, delta, sliceops.XOR)
+		if err != nil {
+			return nil, err
+		}
+		copy(target, flipped)
+	}
+
+	return result, nil
+}
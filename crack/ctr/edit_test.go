@@ -0,0 +1,83 @@
+package ctr
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/Xjs/cryptopals/crack/cbc"
+	"github.com/Xjs/cryptopals/oracle"
+)
+
+// TestRecoverWithEdit is challenge 25
+func TestRecoverWithEdit(t *testing.T) {
+	o, err := oracle.NewCTREdit(rand.New(rand.NewSource(25)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext := []byte(strings.Repeat("I'm back and I'm ringin' the bell \n", 20))
+	ciphertext, err := o.Encrypt(plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := RecoverWithEdit(o, ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Errorf("RecoverWithEdit() = %q, want %q", got, plaintext)
+	}
+}
+
+// TestBitflipChallenge26 is challenge 26
+func TestBitflipChallenge26(t *testing.T) {
+	o, err := oracle.NewComments(rand.New(rand.NewSource(26)), oracle.CTR)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	known := []byte("XadminXtrueX")
+	ciphertext, err := o.Encrypt(known)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := o.IsAdmin(ciphertext); ok {
+		t.Fatal("oracle did not quote its input")
+	}
+
+	forged, err := Bitflip(ciphertext, cbc.Edit{
+		Offset:  len(oracle.CommentsPrefix),
+		Known:   known,
+		Desired: []byte(";admin=true;"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, err := o.IsAdmin(forged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ok {
+		t.Error("forged ciphertext is not admin")
+	}
+}
+
+func TestBitflipErrors(t *testing.T) {
+	ciphertext := make([]byte, 10)
+	tests := []struct {
+		name string
+		edit cbc.Edit
+		want error
+	}{
+		{"length", cbc.Edit{Offset: 0, Known: []byte("ab"), Desired: []byte("abc")}, cbc.ErrEditLength},
+		{"range", cbc.Edit{Offset: 8, Known: []byte("abc"), Desired: []byte("xyz")}, cbc.ErrEditRange},
+		{"negative", cbc.Edit{Offset: -1, Known: []byte("a"), Desired: []byte("b")}, cbc.ErrEditRange},
+	}
+	for _, tt := range tests {
+		if _, err := Bitflip(ciphertext, tt.edit); err != tt.want {
+			t.Errorf("%s: Bitflip() error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
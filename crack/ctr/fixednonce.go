@@ -0,0 +1,155 @@
+// Package ctr implements attacks against block ciphers in CTR mode.
+package ctr
+
+import (
+	"errors"
+	"math"
+
+	"github.com/Xjs/cryptopals/crack/english"
+	"github.com/Xjs/cryptopals/statistics"
+)
+
+// ErrNoCiphertexts is returned if there is nothing to analyse
+var ErrNoCiphertexts = errors.New("ctr: no ciphertexts")
+
+// Options configure BreakFixedNonce. The zero value is usable.
+type Options struct {
+	// Truncate limits the key stream to the length of the shortest ciphertext,
+	// so that every column has a sample from every ciphertext.
+	Truncate bool
+	// MinSamples is the number of samples below which a column is solved by
+	// bigram scoring with the preceding column instead of by byte frequency.
+	// Defaults to 8.
+	MinSamples int
+	// Scorer rates the columns solved by frequency. Defaults to english.DefaultScorer.
+	Scorer english.Scorer
+	// Bigrams rates columns with few samples. Defaults to english.BigramScorer.
+	Bigrams english.Scorer
+}
+
+func (o *Options) withDefaults() Options {
+	var result Options
+	if o != nil {
+		result = *o
+	}
+	if result.MinSamples <= 0 {
+		result.MinSamples = 8
+	}
+	if result.Scorer == nil {
+		result.Scorer = english.DefaultScorer
+	}
+	if result.Bigrams == nil {
+		result.Bigrams = english.BigramScorer
+	}
+	return result
+}
+
+// A Result is the outcome of BreakFixedNonce
+type Result struct {
+	Keystream []byte
+	// Confidence holds, for each key stream byte, the margin between the score
+	// of the chosen byte and the runner-up relative to the larger of both, in [0, 1]
+	Confidence []float64
+	// Samples holds, for each key stream byte, the number of ciphertexts that cover it
+	Samples []int
+}
+
+// Decrypt XORs ciphertext with the recovered key stream, as far as it reaches
+func (r *Result) Decrypt(ciphertext []byte) []byte {
+	result := make([]byte, len(ciphertext))
+	for i, c := range ciphertext {
+		if i < len(r.Keystream) {
+			result[i] = c ^ r.Keystream[i]
+		} else {
+			result[i] = c
+		}
+	}
+	return result
+}
+
+// BreakFixedNonce recovers the key stream shared by ciphertexts that were
+// encrypted in CTR mode with the same key and nonce (challenges 19 and 20).
+// Since they were all XORed with the same key stream, this is the same as
+// repeating-key XOR with a known key size: every column of aligned ciphertext
+// bytes is single-byte XOR, solved with english.FindSingleByteKeyCandidates.
+// Towards the end of the longest ciphertexts, columns have too few samples for
+// frequency analysis; they are solved by choosing the byte that gives the most
+// likely bigrams together with the already decrypted previous column.
+func BreakFixedNonce(ciphertexts [][]byte, opts *Options) (*Result, error) {
+	o := opts.withDefaults()
+	if len(ciphertexts) == 0 {
+		return nil, ErrNoCiphertexts
+	}
+
+	length := len(ciphertexts[0])
+	for _, c := range ciphertexts {
+		if (o.Truncate && len(c) < length) || (!o.Truncate && len(c) > length) {
+			length = len(c)
+		}
+	}
+
+	r := &Result{
+		Keystream:  make([]byte, length),
+		Confidence: make([]float64, length),
+		Samples:    make([]int, length),
+	}
+	for i := 0; i < length; i++ {
+		var column []byte
+		for _, c := range ciphertexts {
+			if i < len(c) {
+				column = append(column, c[i])
+			}
+		}
+		r.Samples[i] = len(column)
+
+		var hist statistics.ByteScoreHistogram
+		if len(column) >= o.MinSamples || i == 0 {
+			hist, _ = english.FindSingleByteKeyCandidates(column, 2, o.Scorer)
+		} else {
+			hist = bigramCandidates(ciphertexts, r.Keystream, i, o.Bigrams)
+		}
+
+		best := hist.GetHigh(0)
+		r.Keystream[i] = best.Byte
+		r.Confidence[i] = margin(best.Score, hist.GetHigh(1).Score)
+	}
+
+	return r, nil
+}
+
+// bigramCandidates scores all 256 candidates for key stream byte i by the sum
+// of the bigram scores of the decrypted previous and current byte of each ciphertext.
+// Bigram scorers usually ignore case, so english.RankCandidates breaks ties with
+// the decrypted column.
+func bigramCandidates(ciphertexts [][]byte, keystream []byte, i int, scorer english.Scorer) statistics.ByteScoreHistogram {
+	hist := make(statistics.ByteScoreHistogram, 0, 256)
+	for g := 255; g >= 0; g-- {
+		var sum statistics.Score
+		for _, c := range ciphertexts {
+			if i < len(c) {
+				sum += scorer.Score([]byte{c[i-1] ^ keystream[i-1], c[i] ^ byte(g)})
+			}
+		}
+		hist = append(hist, statistics.ByteScoreHistogramEntry{Byte: byte(g), Score: sum})
+	}
+	english.RankCandidates(hist, scorer, func(g byte) []byte {
+		var column []byte
+		for _, c := range ciphertexts {
+			if i < len(c) {
+				column = append(column, c[i]^g)
+			}
+		}
+		return column
+	})
+	return hist
+}
+
+// margin returns (best-second)/max(|best|, |second|), limited to [0, 1]
+func margin(best, second statistics.Score) float64 {
+	b, s := float64(best), float64(second)
+	d := math.Max(math.Abs(b), math.Abs(s))
+	if d == 0 {
+		return 0
+	}
+	return math.Max(0, math.Min(1, (b-s)/d))
+}
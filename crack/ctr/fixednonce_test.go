@@ -0,0 +1,142 @@
+package ctr
+
+import (
+	"bytes"
+	"crypto/aes"
+	"strings"
+	"testing"
+
+	"github.com/Xjs/cryptopals/crack/english"
+	"github.com/Xjs/cryptopals/modes"
+)
+
+// encryptLines encrypts each line with AES-CTR under the same key and nonce
+func encryptLines(t *testing.T, lines []string) ([][]byte, []byte) {
+	block, err := aes.NewCipher([]byte("YELLOW SUBMARINE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var longest int
+	var ciphertexts [][]byte
+	for _, line := range lines {
+		ctr, err := modes.NewCTR(block, make([]byte, aes.BlockSize), modes.Challenge18Layout)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c := make([]byte, len(line))
+		ctr.XORKeyStream(c, []byte(line))
+		ciphertexts = append(ciphertexts, c)
+		if len(line) > longest {
+			longest = len(line)
+		}
+	}
+
+	ctr, err := modes.NewCTR(block, make([]byte, aes.BlockSize), modes.Challenge18Layout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keystream := make([]byte, longest)
+	ctr.XORKeyStream(keystream, keystream)
+	return ciphertexts, keystream
+}
+
+// sentences splits held-out text, which the scorers are not built from, into
+// lines of similar length
+func sentences() []string {
+	var result []string
+	words := strings.Fields(english.HeldOutText)
+	for i := 0; i < len(words); i += 9 {
+		end := i + 9 + i%4
+		if end > len(words) {
+			end = len(words)
+		}
+		result = append(result, strings.Join(words[i:end], " "))
+	}
+	return result
+}
+
+func TestBreakFixedNonce(t *testing.T) {
+	lines := sentences()
+	ciphertexts, keystream := encryptLines(t, lines)
+
+	r, err := BreakFixedNonce(ciphertexts, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(r.Keystream) != len(keystream) {
+		t.Fatalf("key stream length %d, want %d", len(r.Keystream), len(keystream))
+	}
+
+	var correct, wellSampled, correctWellSampled int
+	for i := range keystream {
+		ok := r.Keystream[i] == keystream[i]
+		if ok {
+			correct++
+		}
+		if r.Samples[i] >= 8 {
+			wellSampled++
+			if ok {
+				correctWellSampled++
+			}
+		}
+		if r.Confidence[i] < 0 || r.Confidence[i] > 1 {
+			t.Errorf("column %d: confidence %v not in [0, 1]", i, r.Confidence[i])
+		}
+	}
+	t.Logf("%d lines: %d of %d key stream bytes correct, %d of %d well-sampled ones",
+		len(lines), correct, len(keystream), correctWellSampled, wellSampled)
+	if float64(correctWellSampled) < 0.9*float64(wellSampled) {
+		t.Errorf("only %d of %d well-sampled columns correct", correctWellSampled, wellSampled)
+	}
+	if float64(correct) < 0.8*float64(len(keystream)) {
+		t.Errorf("only %d of %d columns correct", correct, len(keystream))
+	}
+	t.Logf("%q", r.Decrypt(ciphertexts[0]))
+
+	for n := 0; n < 5; n++ {
+		again, err := BreakFixedNonce(ciphertexts, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(again.Keystream, r.Keystream) {
+			t.Fatalf("BreakFixedNonce() is not deterministic: %x != %x", again.Keystream, r.Keystream)
+		}
+	}
+}
+
+func TestBreakFixedNonceTruncate(t *testing.T) {
+	ciphertexts, keystream := encryptLines(t, sentences())
+	r, err := BreakFixedNonce(ciphertexts, &Options{Truncate: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	shortest := len(ciphertexts[0])
+	for _, c := range ciphertexts {
+		if len(c) < shortest {
+			shortest = len(c)
+		}
+	}
+	if len(r.Keystream) != shortest {
+		t.Errorf("key stream length %d, want %d", len(r.Keystream), shortest)
+	}
+	for i := range r.Samples {
+		if r.Samples[i] != len(ciphertexts) {
+			t.Errorf("column %d has %d samples, want %d", i, r.Samples[i], len(ciphertexts))
+		}
+	}
+	var correct int
+	for i := range r.Keystream {
+		if r.Keystream[i] == keystream[i] {
+			correct++
+		}
+	}
+	if correct < len(r.Keystream)-1 {
+		t.Errorf("%d of %d columns correct", correct, len(r.Keystream))
+	}
+
+	if _, err := BreakFixedNonce(nil, nil); err != ErrNoCiphertexts {
+		t.Errorf("BreakFixedNonce() error = %v, want %v", err, ErrNoCiphertexts)
+	}
+}
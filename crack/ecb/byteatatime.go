@@ -0,0 +1,206 @@
+// Package ecb implements attacks against block ciphers used in ECB mode.
+package ecb
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+
+	"github.com/Xjs/cryptopals/analysis"
+	"github.com/Xjs/cryptopals/oracle"
+)
+
+var (
+	// ErrBlockSize is returned if the block size of an oracle cannot be determined
+	ErrBlockSize = errors.New("ecb: cannot determine block size")
+	// ErrNotECB is returned if an oracle does not seem to use ECB mode
+	ErrNotECB = errors.New("ecb: oracle does not use ECB mode")
+	// ErrPrefix is returned if the length of an oracle's prefix cannot be determined
+	ErrPrefix = errors.New("ecb: cannot determine prefix length")
+	// ErrNoMatch is returned if a secret byte matches none of the 256 candidates
+	ErrNoMatch = errors.New("ecb: no byte matches")
+)
+
+// maxBlockSize is the largest block size DiscoverBlockSize looks for
+const maxBlockSize = 256
+
+// filler is the byte used to fill chosen plaintexts
+const filler = 'A'
+
+// A ProgressFunc is called after each recovered byte with the bytes recovered
+// so far and the total length of the secret
+type ProgressFunc func(recovered []byte, total int)
+
+// DiscoverBlockSize feeds o with growing inputs until the ciphertext grows,
+// and returns by how much it grew
+func DiscoverBlockSize(o oracle.Oracle) (int, error) {
+	c, err := o.Encrypt(nil)
+	if err != nil {
+		return 0, err
+	}
+	initial := len(c)
+	for i := 1; i <= maxBlockSize; i++ {
+		c, err := o.Encrypt(bytes.Repeat([]byte{filler}, i))
+		if err != nil {
+			return 0, err
+		}
+		if len(c) > initial {
+			return len(c) - initial, nil
+		}
+	}
+	return 0, ErrBlockSize
+}
+
+// DiscoverPrefixLength determines the length of the fixed data that o puts in
+// front of the chosen plaintext. It looks for the smallest input for which two
+// adjacent ciphertext blocks are equal, using analysis.AnalyzeBlocks. Since
+// such a pair may also come from the prefix, or from a secret that continues
+// the filler, a candidate only counts if the same input with a different
+// filler byte gives a pair at the same offset, and the block changes with it.
+func DiscoverPrefixLength(o oracle.Oracle, blockSize int) (int, error) {
+	for k := 0; k < blockSize; k++ {
+		c, err := o.Encrypt(bytes.Repeat([]byte{filler}, k+2*blockSize))
+		if err != nil {
+			return 0, err
+		}
+		check, err := o.Encrypt(bytes.Repeat([]byte{filler + 1}, k+2*blockSize))
+		if err != nil {
+			return 0, err
+		}
+		report, err := analysis.AnalyzeBlocks(c, blockSize)
+		if err != nil {
+			return 0, err
+		}
+
+		for _, offset := range adjacentRepeats(report) {
+			index := offset / blockSize
+			pair := block(check, index, blockSize)
+			if pair != nil && bytes.Equal(pair, block(check, index+1, blockSize)) &&
+				!bytes.Equal(pair, block(c, index, blockSize)) {
+				return offset - k, nil
+			}
+		}
+	}
+	return 0, ErrPrefix
+}
+
+// adjacentRepeats returns the offsets of all blocks that are immediately
+// followed by a copy of themselves, in ascending order
+func adjacentRepeats(report *analysis.Report) []int {
+	var result []int
+	for _, r := range report.Repeated {
+		for i := 1; i < len(r.Offsets); i++ {
+			if r.Offsets[i]-r.Offsets[i-1] == report.BlockSize {
+				result = append(result, r.Offsets[i-1])
+			}
+		}
+	}
+	sort.Ints(result)
+	return result
+}
+
+// ByteAtATime recovers the secret that o appends to the chosen plaintext before
+// encrypting it in ECB mode (challenge 12). It copes with a fixed prefix of
+// unknown length in front of the chosen plaintext (challenge 14). progress may be nil.
+func ByteAtATime(o oracle.Oracle, progress ProgressFunc) ([]byte, error) {
+	blockSize, err := DiscoverBlockSize(o)
+	if err != nil {
+		return nil, err
+	}
+
+	detection, err := oracle.DetectMode(o, blockSize)
+	if err != nil {
+		return nil, err
+	}
+	if detection.Mode != oracle.ECB {
+		return nil, ErrNotECB
+	}
+
+	prefixLen, err := DiscoverPrefixLength(o, blockSize)
+	if err != nil {
+		return nil, err
+	}
+
+	// align pads the prefix to a block boundary; skip is the number of blocks
+	// taken up by the padded prefix
+	align := (blockSize - prefixLen%blockSize) % blockSize
+	skip := (prefixLen + align) / blockSize
+
+	secretLen, err := secretLength(o, blockSize, prefixLen, align)
+	if err != nil {
+		return nil, err
+	}
+
+	// known always holds blockSize-1 bytes of filler in front of the recovered secret
+	known := bytes.Repeat([]byte{filler}, blockSize-1)
+	alignment := bytes.Repeat([]byte{filler}, align)
+	for n := 0; n < secretLen; n++ {
+		window := known[len(known)-(blockSize-1):]
+
+		// One query holds all 256 candidate blocks: window || guess
+		dictInput := append([]byte(nil), alignment...)
+		for g := 0; g < 256; g++ {
+			dictInput = append(dictInput, window...)
+			dictInput = append(dictInput, byte(g))
+		}
+		dict, err := o.Encrypt(dictInput)
+		if err != nil {
+			return nil, err
+		}
+
+		// Shift the secret so that byte n is the last one of its block
+		shift := blockSize - 1 - n%blockSize
+		c, err := o.Encrypt(append(append([]byte(nil), alignment...), known[:shift]...))
+		if err != nil {
+			return nil, err
+		}
+		target := block(c, skip+n/blockSize, blockSize)
+
+		match := -1
+		for g := 0; g < 256; g++ {
+			if bytes.Equal(block(dict, skip+g, blockSize), target) {
+				match = g
+				break
+			}
+		}
+		if match < 0 {
+			return nil, ErrNoMatch
+		}
+
+		known = append(known, byte(match))
+		if progress != nil {
+			progress(known[blockSize-1:], secretLen)
+		}
+	}
+
+	return known[blockSize-1:], nil
+}
+
+// secretLength finds the length of the secret by growing the input until a
+// new padding block appears
+func secretLength(o oracle.Oracle, blockSize, prefixLen, align int) (int, error) {
+	alignment := bytes.Repeat([]byte{filler}, align)
+	c, err := o.Encrypt(alignment)
+	if err != nil {
+		return 0, err
+	}
+	initial := len(c)
+	for i := 1; i <= blockSize; i++ {
+		c, err := o.Encrypt(append(append([]byte(nil), alignment...), bytes.Repeat([]byte{filler}, i)...))
+		if err != nil {
+			return 0, err
+		}
+		if len(c) > initial {
+			return initial - prefixLen - align - i, nil
+		}
+	}
+	return 0, ErrBlockSize
+}
+
+// block returns the index-th block of c, or nil if c is too short
+func block(c []byte, index, blockSize int) []byte {
+	if (index+1)*blockSize > len(c) {
+		return nil
+	}
+	return c[index*blockSize : (index+1)*blockSize]
+}
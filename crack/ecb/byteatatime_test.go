@@ -0,0 +1,103 @@
+package ecb
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/base64"
+	"math/rand"
+	"testing"
+
+	"github.com/Xjs/cryptopals/modes"
+	"github.com/Xjs/cryptopals/oracle"
+	"github.com/Xjs/cryptopals/padding"
+)
+
+const challenge12Secret = "Um9sbGluJyBpbiBteSA1LjAKV2l0aCBteSByYWctdG9wIGRvd24gc28gbXkg" +
+	"aGFpciBjYW4gYmxvdwpUaGUgZ2lybGllcyBvbiBzdGFuZGJ5IHdhdmluZyBq" +
+	"dXN0IHRvIHNheSBoaQpEaWQgeW91IHN0b3A/IE5vLCBJIGp1c3QgZHJvdmUg" +
+	"YnkK"
+
+func TestByteAtATime(t *testing.T) {
+	secret, err := base64.StdEncoding.DecodeString(challenge12Secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		seed      int64
+		maxPrefix int
+		secret    []byte
+	}{
+		{"challenge-12", 1, 0, secret},
+		{"challenge-14", 2, 64, secret},
+		{"challenge-14-short-prefix", 3, 5, secret},
+		{"challenge-14-long-prefix", 4, 200, secret},
+		{"challenge-14-secret-starts-with-filler", 5, 40, []byte("A secret starting with A")},
+		{"challenge-14-secret-of-filler", 6, 40, []byte("AAAAAAAAAAAAAAAAAAAAbc")},
+		{"challenge-14-secret-starts-with-check", 7, 40, []byte("Bob's secret")},
+	}
+	for _, tt := range tests {
+		secret := tt.secret
+		t.Run(tt.name, func(t *testing.T) {
+			o, err := oracle.NewAppendSecret(rand.New(rand.NewSource(tt.seed)), secret, tt.maxPrefix)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var calls, total int
+			got, err := ByteAtATime(o, func(recovered []byte, n int) {
+				calls++
+				total = n
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(got, secret) {
+				t.Errorf("ByteAtATime() = %q, want %q", got, secret)
+			}
+			if calls != len(secret) || total != len(secret) {
+				t.Errorf("progress called %d times with total %d, want %d", calls, total, len(secret))
+			}
+		})
+	}
+}
+
+func TestDiscoverPrefixLength(t *testing.T) {
+	// A prefix ending in filler bytes must not look shorter than it is
+	for _, prefix := range []string{"", "x", "xyzAA", "0123456789abcdefAAA"} {
+		o := oracle.OracleFunc(func(p []byte) ([]byte, error) {
+			return identityECB(append([]byte(prefix), p...)), nil
+		})
+		got, err := DiscoverPrefixLength(o, aes.BlockSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != len(prefix) {
+			t.Errorf("DiscoverPrefixLength(%q) = %d, want %d", prefix, got, len(prefix))
+		}
+	}
+}
+
+func TestByteAtATimeNotECB(t *testing.T) {
+	block, err := aes.NewCipher([]byte("YELLOW SUBMARINE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cbc := oracle.OracleFunc(func(p []byte) ([]byte, error) {
+		padded, err := padding.Pad(append(append([]byte(nil), p...), "secret"...), aes.BlockSize)
+		if err != nil {
+			return nil, err
+		}
+		return modes.EncryptCBC(block, make([]byte, aes.BlockSize), padded)
+	})
+	if _, err := ByteAtATime(cbc, nil); err != ErrNotECB {
+		t.Errorf("ByteAtATime() error = %v, want %v", err, ErrNotECB)
+	}
+}
+
+// identityECB pads p to full blocks; it acts like ECB with the identity as block cipher
+func identityECB(p []byte) []byte {
+	n := aes.BlockSize - len(p)%aes.BlockSize
+	return append(p, bytes.Repeat([]byte{byte(n)}, n)...)
+}
@@ -0,0 +1,59 @@
+package ecb
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/Xjs/cryptopals/oracle"
+)
+
+// ErrBlockRange is returned if a Piece refers to blocks beyond the ciphertext
+var ErrBlockRange = errors.New("ecb: block range out of bounds")
+
+// A Piece selects Count ciphertext blocks, starting at block First, from the
+// encryption of Input by an oracle
+type Piece struct {
+	Input        []byte
+	First, Count int
+}
+
+// Splice encrypts the input of every piece with o and concatenates the selected
+// blocks. Since ECB encrypts every block independently, the result decrypts to
+// the concatenation of the corresponding plaintext blocks.
+func Splice(o oracle.Oracle, blockSize int, pieces ...Piece) ([]byte, error) {
+	var result []byte
+	for _, p := range pieces {
+		c, err := o.Encrypt(p.Input)
+		if err != nil {
+			return nil, err
+		}
+		if p.First < 0 || p.Count < 0 || (p.First+p.Count)*blockSize > len(c) {
+			return nil, ErrBlockRange
+		}
+		result = append(result, c[p.First*blockSize:(p.First+p.Count)*blockSize]...)
+	}
+	return result, nil
+}
+
+// Isolate returns a Piece that encrypts payload in blocks of its own, for an
+// oracle that puts prefixLen bytes in front of the chosen input. The payload is
+// preceded by filler bytes up to the next block boundary. If payload is not a
+// multiple of blockSize, its last block is shared with whatever follows it.
+func Isolate(payload []byte, prefixLen, blockSize int) Piece {
+	align := (blockSize - prefixLen%blockSize) % blockSize
+	input := append(bytes.Repeat([]byte{filler}, align), payload...)
+	return Piece{
+		Input: input,
+		First: (prefixLen + align) / blockSize,
+		Count: (len(payload) + blockSize - 1) / blockSize,
+	}
+}
+
+// Blocks returns the number of blocks of the encryption of input by o
+func Blocks(o oracle.Oracle, input []byte, blockSize int) (int, error) {
+	c, err := o.Encrypt(input)
+	if err != nil {
+		return 0, err
+	}
+	return len(c) / blockSize, nil
+}
@@ -0,0 +1,78 @@
+package ecb
+
+import (
+	"crypto/aes"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/Xjs/cryptopals/oracle"
+	"github.com/Xjs/cryptopals/padding"
+)
+
+// TestForgeAdminProfile is challenge 13
+func TestForgeAdminProfile(t *testing.T) {
+	o, err := oracle.NewProfile(rand.New(rand.NewSource(13)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	prefixLen, err := DiscoverPrefixLength(o, aes.BlockSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prefixLen != len("email=") {
+		t.Fatalf("prefix length = %d, want %d", prefixLen, len("email="))
+	}
+
+	// A block that decrypts to "admin" with valid padding
+	admin, err := padding.Pad([]byte("admin"), aes.BlockSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	adminPiece := Isolate(admin, prefixLen, aes.BlockSize)
+
+	// An email that ends "...&role=" at a block boundary
+	const rest = "&uid=10&role="
+	emailLen := (aes.BlockSize - (prefixLen+len(rest))%aes.BlockSize) % aes.BlockSize
+	emailLen += aes.BlockSize // make room for a plausible address
+	email := strings.Repeat("x", emailLen-len("@bar.com")) + "@bar.com"
+	n, err := Blocks(o, []byte(email), aes.BlockSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	forged, err := Splice(o, aes.BlockSize,
+		Piece{Input: []byte(email), First: 0, Count: n - 1},
+		adminPiece,
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	profile, err := o.Decrypt(forged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if profile.Get("role") != "admin" || profile.Get("email") != email {
+		t.Errorf("forged profile = %v", profile)
+	}
+}
+
+func TestSpliceRange(t *testing.T) {
+	identity := oracle.OracleFunc(func(p []byte) ([]byte, error) { return identityECB(p), nil })
+	got, err := Splice(identity, aes.BlockSize,
+		Piece{Input: []byte("0123456789abcdefXXXXXXXXXXXXXXXX"), First: 1, Count: 1},
+		Piece{Input: []byte("0123456789abcdef"), First: 0, Count: 1},
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "XXXXXXXXXXXXXXXX0123456789abcdef" {
+		t.Errorf("Splice() = %q", got)
+	}
+
+	if _, err := Splice(identity, aes.BlockSize, Piece{Input: nil, First: 1, Count: 1}); err != ErrBlockRange {
+		t.Errorf("Splice() error = %v, want %v", err, ErrBlockRange)
+	}
+}
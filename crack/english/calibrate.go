@@ -0,0 +1,334 @@
+package english
+
+import (
+	_ "embed"
+	"errors"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+
+	"github.com/Xjs/cryptopals/statistics"
+	"github.com/Xjs/cryptopals/xor"
+)
+
+// ErrNoSamples is returned by Calibrate if a length bucket lacks English
+// or non-English samples
+var ErrNoSamples = errors.New("english: not enough samples to calibrate")
+
+// HeldOutText is English prose that none of the scorers of this package are
+// built from. Calibrating on it measures error rates out of sample.
+//
+//go:embed heldout.txt
+var HeldOutText string
+
+// ForeignText is German and French prose, used as non-English samples for calibration
+//
+//go:embed foreign.txt
+var ForeignText string
+
+// A Sample is a labelled text used for calibration
+type Sample struct {
+	Text    []byte
+	English bool
+}
+
+// GenerateSamples creates n English and n non-English samples for each of the
+// given lengths. English samples are random excerpts of corpus. Non-English
+// samples are evenly split between random bytes, random printable ASCII,
+// excerpts XORed with a random non-zero byte, the latter resembling a
+// decryption with the wrong single-byte key, and, unless foreign is empty,
+// excerpts of foreign, which should be text in other languages.
+func GenerateSamples(corpus, foreign string, lengths []int, n int, rng *rand.Rand) []Sample {
+	kinds := 3
+	if foreign != "" {
+		kinds = 4
+	}
+
+	var result []Sample
+	for _, length := range lengths {
+		if length > len(corpus) || (foreign != "" && length > len(foreign)) {
+			continue
+		}
+		for i := 0; i < n; i++ {
+			start := rng.Intn(len(corpus) - length + 1)
+			excerpt := []byte(corpus[start : start+length])
+			result = append(result, Sample{Text: excerpt, English: true})
+
+			garbage := make([]byte, length)
+			switch i % kinds {
+			case 0:
+				rng.Read(garbage)
+			case 1:
+				for j := range garbage {
+					garbage[j] = byte(0x20 + rng.Intn(0x7f-0x20))
+				}
+			case 2:
+				garbage = xor.Single(excerpt, byte(1+rng.Intn(255)))
+			case 3:
+				start := rng.Intn(len(foreign) - length + 1)
+				garbage = []byte(foreign[start : start+length])
+			}
+			result = append(result, Sample{Text: garbage})
+		}
+	}
+	return result
+}
+
+// A ROCPoint is a point on a receiver operating characteristic curve: the rates
+// of English (true positive) and non-English (false positive) samples whose
+// score is at least Threshold.
+type ROCPoint struct {
+	Threshold         statistics.Score
+	TruePositiveRate  float64
+	FalsePositiveRate float64
+}
+
+// ROC computes the receiver operating characteristic of scorer on the given
+// samples, with one point per distinct sample score, ordered by descending threshold.
+func ROC(scorer Scorer, samples []Sample) []ROCPoint {
+	scored := scoreSamples(scorer, samples)
+	sort.Slice(scored, func(a, b int) bool { return scored[a].score > scored[b].score })
+
+	var positives, negatives int
+	for _, s := range scored {
+		if s.english {
+			positives++
+		} else {
+			negatives++
+		}
+	}
+
+	var result []ROCPoint
+	var tp, fp int
+	for i, s := range scored {
+		if s.english {
+			tp++
+		} else {
+			fp++
+		}
+		if i+1 < len(scored) && scored[i+1].score == s.score {
+			continue
+		}
+		result = append(result, ROCPoint{
+			Threshold:         s.score,
+			TruePositiveRate:  rate(tp, positives),
+			FalsePositiveRate: rate(fp, negatives),
+		})
+	}
+	return result
+}
+
+// AUC returns the area under a ROC curve as returned by ROC
+func AUC(roc []ROCPoint) float64 {
+	var area, lastTPR, lastFPR float64
+	for _, p := range roc {
+		area += (p.FalsePositiveRate - lastFPR) * (p.TruePositiveRate + lastTPR) / 2
+		lastTPR, lastFPR = p.TruePositiveRate, p.FalsePositiveRate
+	}
+	return area
+}
+
+// A Threshold is a calibrated decision boundary for texts of a range of lengths
+type Threshold struct {
+	// MinLength is the shortest text length the threshold applies to. It applies
+	// up to the MinLength of the next Threshold of a Detector.
+	MinLength int
+	// Score is the lowest score considered English
+	Score statistics.Score
+	// FalsePositiveRate and FalseNegativeRate are the error rates measured on
+	// the calibration samples
+	FalsePositiveRate, FalseNegativeRate float64
+	// Scale is the spread of the scores around Score, used for Confidence
+	Scale float64
+}
+
+// A Detector decides whether texts are English using thresholds calibrated per
+// text length. It is a Classifier that ranks texts like its Scorer.
+type Detector struct {
+	Scorer     Scorer
+	Thresholds []Threshold
+}
+
+// Score returns d.Scorer.Score(text)
+func (d *Detector) Score(text []byte) statistics.Score { return d.Scorer.Score(text) }
+
+// Calibrate creates a Detector by finding, for every length bucket, the score
+// threshold that minimises the sum of false positive and false negative rates on
+// the given samples. Buckets start at the given minimum lengths; samples shorter
+// than the first one are counted towards the first bucket. scorer may be nil.
+func Calibrate(scorer Scorer, samples []Sample, bucketLengths []int) (*Detector, error) {
+	if scorer == nil {
+		scorer = DefaultScorer
+	}
+	lengths := append([]int(nil), bucketLengths...)
+	sort.Ints(lengths)
+	if len(lengths) == 0 {
+		lengths = []int{0}
+	}
+
+	d := &Detector{Scorer: scorer}
+	buckets := make([][]Sample, len(lengths))
+	for _, s := range samples {
+		i := bucketIndex(lengths, len(s.Text))
+		buckets[i] = append(buckets[i], s)
+	}
+
+	for i, bucket := range buckets {
+		t, err := calibrateBucket(scorer, bucket)
+		if err != nil {
+			return nil, err
+		}
+		t.MinLength = lengths[i]
+		d.Thresholds = append(d.Thresholds, t)
+	}
+
+	return d, nil
+}
+
+func calibrateBucket(scorer Scorer, samples []Sample) (Threshold, error) {
+	var pos, neg []float64
+	for _, s := range scoreSamples(scorer, samples) {
+		if math.IsInf(float64(s.score), 0) || math.IsNaN(float64(s.score)) {
+			// Infinite scores say nothing about where the threshold lies
+			continue
+		}
+		if s.english {
+			pos = append(pos, float64(s.score))
+		} else {
+			neg = append(neg, float64(s.score))
+		}
+	}
+	if len(pos) == 0 || len(neg) == 0 {
+		return Threshold{}, ErrNoSamples
+	}
+	sort.Float64s(pos)
+	sort.Float64s(neg)
+
+	best := Threshold{FalsePositiveRate: 1, FalseNegativeRate: 1}
+	candidates := append(append([]float64(nil), pos...), neg...)
+	for _, c := range candidates {
+		fn := rate(sort.SearchFloat64s(pos, c), len(pos))
+		fp := 1 - rate(sort.SearchFloat64s(neg, c), len(neg))
+		if fp+fn < best.FalsePositiveRate+best.FalseNegativeRate {
+			best = Threshold{Score: statistics.Score(c), FalsePositiveRate: fp, FalseNegativeRate: fn}
+		}
+	}
+
+	best.Scale = (stddev(pos) + stddev(neg)) / 4
+	if best.Scale == 0 {
+		best.Scale = 1
+	}
+	return best, nil
+}
+
+// threshold returns the Threshold that applies to texts of the given length
+func (d *Detector) threshold(length int) Threshold {
+	lengths := make([]int, len(d.Thresholds))
+	for i, t := range d.Thresholds {
+		lengths[i] = t.MinLength
+	}
+	return d.Thresholds[bucketIndex(lengths, length)]
+}
+
+// Confidence returns how confident the detector is that text is English, between
+// 0 and 1. It is 0.5 for texts that score exactly at the calibrated threshold.
+func (d *Detector) Confidence(text []byte) float64 {
+	t := d.threshold(len(text))
+	score := float64(d.Scorer.Score(text))
+	if math.IsNaN(score) {
+		return 0
+	}
+	return 1 / (1 + math.Exp(-(score-float64(t.Score))/t.Scale))
+}
+
+// IsEnglish returns whether text scores at least the calibrated threshold for its length
+func (d *Detector) IsEnglish(text []byte) bool {
+	return d.Scorer.Score(text) >= d.threshold(len(text)).Score
+}
+
+var (
+	defaultDetector      *Detector
+	defaultDetectorOnce  sync.Once
+	languageDetector     *Detector
+	languageDetectorOnce sync.Once
+)
+
+// DefaultBucketLengths are the length buckets used by DefaultDetector and LanguageDetector
+var DefaultBucketLengths = []int{0, 8, 16, 32, 64, 128, 256}
+
+// calibrationLengths are the sample lengths DefaultDetector and LanguageDetector are calibrated on
+var calibrationLengths = []int{4, 8, 12, 16, 24, 32, 48, 64, 96, 128, 192, 256, 384}
+
+// DefaultDetector returns a Detector for DefaultScorer, calibrated on samples
+// generated deterministically from HeldOutText by GenerateSamples.
+// FindSingleByteKey uses it to decide whether a decryption is English.
+// It tells English from garbage and wrong decryptions, but rune frequencies
+// are too similar to reject other languages in Latin script.
+func DefaultDetector() *Detector {
+	defaultDetectorOnce.Do(func() {
+		defaultDetector = calibrateDefault(DefaultScorer, "")
+	})
+	return defaultDetector
+}
+
+// LanguageDetector returns a Detector for BigramScorer, calibrated like
+// DefaultDetector but with excerpts of ForeignText among the non-English
+// samples, so that it also rejects other languages. IsEnglish uses it.
+func LanguageDetector() *Detector {
+	languageDetectorOnce.Do(func() {
+		languageDetector = calibrateDefault(BigramScorer, ForeignText)
+	})
+	return languageDetector
+}
+
+func calibrateDefault(scorer Scorer, foreign string) *Detector {
+	samples := GenerateSamples(HeldOutText, foreign, calibrationLengths, 150, rand.New(rand.NewSource(1)))
+	d, err := Calibrate(scorer, samples, DefaultBucketLengths)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+type scoredSample struct {
+	score   statistics.Score
+	english bool
+}
+
+func scoreSamples(scorer Scorer, samples []Sample) []scoredSample {
+	result := make([]scoredSample, len(samples))
+	for i, s := range samples {
+		result[i] = scoredSample{scorer.Score(s.Text), s.English}
+	}
+	return result
+}
+
+// bucketIndex returns the index of the last bucket starting at or below length
+func bucketIndex(lengths []int, length int) int {
+	i := sort.SearchInts(lengths, length+1) - 1
+	if i < 0 {
+		i = 0
+	}
+	return i
+}
+
+func rate(count, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(count) / float64(total)
+}
+
+func stddev(values []float64) float64 {
+	var mean float64
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	var variance float64
+	for _, v := range values {
+		variance += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(variance / float64(len(values)))
+}
@@ -0,0 +1,101 @@
+package english
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/Xjs/cryptopals/xor"
+)
+
+func TestROC(t *testing.T) {
+	scorers := []struct {
+		name   string
+		scorer Scorer
+		// minAUC is for lengths of at least 32, against garbage and against
+		// garbage and other languages
+		minAUC, minForeignAUC float64
+	}{
+		{"rune-frequency", DefaultScorer, 0.95, 0.9},
+		{"chi-squared", LetterChiSquaredScorer, 0.95, 0.95},
+		{"bigram", BigramScorer, 0.95, 0.95},
+		// Printable texts all score 1, however unlike English they are
+		{"printable", PrintableScorer{}, 0.75, 0.75},
+	}
+	rng := rand.New(rand.NewSource(42))
+	for _, foreign := range []string{"", ForeignText} {
+		for _, length := range []int{8, 32, 128} {
+			samples := GenerateSamples(HeldOutText, foreign, []int{length}, 300, rng)
+			for _, tt := range scorers {
+				name, minAUC := tt.name, tt.minAUC
+				if foreign != "" {
+					name, minAUC = tt.name+" with foreign", tt.minForeignAUC
+				}
+				roc := ROC(tt.scorer, samples)
+				auc := AUC(roc)
+				t.Logf("%s, length %d: AUC %.3f over %d points", name, length, auc, len(roc))
+				for i := 0; i < len(roc); i += len(roc)/8 + 1 {
+					t.Logf("  threshold %8.2f: TPR %.3f, FPR %.3f", roc[i].Threshold, roc[i].TruePositiveRate, roc[i].FalsePositiveRate)
+				}
+				if length >= 32 && auc < minAUC {
+					t.Errorf("%s, length %d: AUC = %.3f, want >= %.2f", name, length, auc, minAUC)
+				}
+			}
+		}
+	}
+}
+
+func TestDetector(t *testing.T) {
+	d := DefaultDetector()
+	for _, th := range d.Thresholds {
+		t.Logf("length >= %d: threshold %.2f, FPR %.3f, FNR %.3f", th.MinLength, th.Score, th.FalsePositiveRate, th.FalseNegativeRate)
+	}
+
+	tests := []struct {
+		name string
+		text string
+		want bool
+	}{
+		{"wristwatch", "I wish to watch my Irish wristwatch", true},
+		{"reference", EnglishText, true},
+		{"japanese", "ごめんなさい！", false},
+		{"garbage", "f240t9ujgn elnfi u2pweiodwd qeu109mq dssd lak;lkasd ckj", false},
+		{"binary", "\x8a\x01\xff\x13\x9c\xe0\x00\x7f\x42\x99\xd1\x05\x66\xab\xcd\xef", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := d.Confidence([]byte(tt.text))
+			if c < 0 || c > 1 {
+				t.Errorf("Confidence() = %v, not in [0, 1]", c)
+			}
+			if got := d.IsEnglish([]byte(tt.text)); got != tt.want {
+				t.Errorf("IsEnglish() = %v, want %v (confidence %.3f)", got, tt.want, c)
+			}
+			if (c >= 0.5) != tt.want {
+				t.Errorf("Confidence() = %.3f, want it on the %v side of 0.5", c, tt.want)
+			}
+		})
+	}
+}
+
+func TestCalibrateNoSamples(t *testing.T) {
+	samples := []Sample{{Text: []byte("only english"), English: true}}
+	if _, err := Calibrate(nil, samples, nil); err != ErrNoSamples {
+		t.Errorf("Calibrate() error = %v, want %v", err, ErrNoSamples)
+	}
+}
+
+func TestFindSingleByteKeyUsesDetector(t *testing.T) {
+	short := []byte("Now is the winter of our discontent")
+	if _, key, _, err := FindSingleByteKey(xor.Single(short, 0x42), nil); err != nil || key != 0x42 {
+		t.Errorf("FindSingleByteKey(English) = %#x, %v", key, err)
+	}
+
+	garbage := make([]byte, len(short))
+	rand.New(rand.NewSource(6)).Read(garbage)
+	if _, _, _, err := FindSingleByteKey(garbage, nil); err == nil {
+		t.Error("FindSingleByteKey(random bytes) accepted a key")
+	}
+	if _, _, _, err := FindSingleByteKey(garbage, DefaultDetector()); err == nil {
+		t.Error("FindSingleByteKey(random bytes, DefaultDetector()) accepted a key")
+	}
+}
@@ -2,7 +2,9 @@ package english
 
 import (
 	"fmt"
+	"sort"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/Xjs/cryptopals/statistics"
 	"github.com/Xjs/cryptopals/xor"
@@ -57,12 +59,13 @@ func GetScore(text string, reference map[rune]int) statistics.Score {
 //
 // The input will be split in keylen blocks, starting at 0..keylen-1 and
 // always skipping keylen bytes; this assumes a rotating key of given length.
-// Decryption of each block will be attempted with the FindSingleByteKey function.
+// Decryption of each block will be attempted with the FindSingleByteKey function
+// using the given scorer, which may be nil to use DefaultScorer.
 // If at least keylen/2 blocks can be decoded with a high enough score,
 // TryKeylen will return nil as third result. Otherwise, decryption will still
 // be performed, and results will be returned, but the third parameter will
 // contain an non-nil error.
-func TryKeylen(input []byte, keylen int) ([]byte, int, error) {
+func TryKeylen(input []byte, keylen int, scorer Scorer) ([]byte, int, error) {
 	blocks := make([][]byte, keylen)
 
 	for i, b := range input {
@@ -73,7 +76,7 @@ func TryKeylen(input []byte, keylen int) ([]byte, int, error) {
 
 	var errors int
 	for i, block := range blocks {
-		en, _, _, err := FindSingleByteKey(block)
+		en, _, _, err := FindSingleByteKey(block, scorer)
 		if err != nil {
 			errors++
 		}
@@ -103,55 +106,127 @@ var ReferenceScore statistics.Score
 
 // ScoreDistance is an empirical value that denotes how much distance a text may have to the reference
 // score to be still considered English.
-// Currently chosen via gut feeling.
+//
+// Deprecated: it was chosen via gut feeling; DefaultDetector holds thresholds calibrated on samples.
 const ScoreDistance statistics.Score = 50
 
 // IsEnglishScore returns true if a text with the given score is considered English
+//
+// Deprecated: the score alone ignores the length of the text; use IsEnglish.
 func IsEnglishScore(score statistics.Score) bool {
 	return ReferenceScore-ScoreDistance < score && score < ReferenceScore+ScoreDistance
 }
 
-// IsEnglish returns whether a text is considered modern English based on the rune frequencies
+// IsEnglish returns whether a text is considered modern English based on its
+// bigrams, using the thresholds of LanguageDetector
 func IsEnglish(text string) bool {
-	return IsEnglishScore(GetScore(text, ReferenceHistogram))
+	return LanguageDetector().IsEnglish([]byte(text))
 }
 
 func init() {
 	ReferenceHistogram = GetRuneFrequency(EnglishText)
+	for r := range referenceASCII {
+		referenceASCII[r] = ReferenceHistogram[rune(r)]
+	}
+	referenceRuneError = ReferenceHistogram[utf8.RuneError]
 	ReferenceScore = GetScore(EnglishText, ReferenceHistogram)
 }
 
 // FindSingleByteKey attempts to decrypt a text assumed to be english, xor-encrypted
 // with a single byte key. It will return the decrypted text, the key byte, the
 // score of the text (referenceScore)
-func FindSingleByteKey(input []byte) (string, byte, statistics.Score, error) {
-	scores := make(map[byte]statistics.Score)
+//
+// All 256 keys are rated with scorer, which also finds keys for texts that are
+// not prose, such as JSON, logs or code. If scorer is nil, DefaultDetector is
+// used, which ranks like DefaultScorer.
+// If the scorer is a Classifier that rejects the result, or a Thresholder and
+// the best score is below its threshold, an error is returned along with the result.
+func FindSingleByteKey(input []byte, scorer Scorer) (string, byte, statistics.Score, error) {
+	if scorer == nil {
+		scorer = DefaultDetector()
+	}
+	candidates, _ := FindSingleByteKeyCandidates(input, 1, scorer)
+	high := candidates.GetHigh(0)
 
-	frequencies := statistics.NewByteHistogramFromRunes(GetRuneFrequency(string(input)))
-	referenceFrequencies := statistics.NewByteHistogramFromRunes(ReferenceHistogram)
+	result := string(xor.Single(input, high.Byte))
 
-	const maxDistance = 3
+	var rejected bool
+	switch s := scorer.(type) {
+	case Classifier:
+		rejected = !s.IsEnglish([]byte(result))
+	case Thresholder:
+		rejected = high.Score < s.Threshold()
+	}
+	if rejected {
+		return result, high.Byte, high.Score, fmt.Errorf("%q has score %.f, probably not english", result, high.Score)
+	}
 
-	for _, tuple := range [][2]int{{0, 0}, {1, 1}, {2, 2}, {0, 1}, {1, 0}, {1, 2}, {2, 1}} {
-		encryptedB := frequencies.GetHigh(tuple[0]).Byte
-		plaintextB := referenceFrequencies.GetHigh(tuple[1]).Byte
+	return result, high.Byte, high.Score, nil
+}
 
-		b := encryptedB ^ plaintextB
+// FindSingleByteKeyCandidates decrypts input with all 256 possible single-byte
+// keys, rates the results with scorer (DefaultScorer if nil) and returns the
+// n best keys. The histogram is sorted like statistics.NewByteScoreHistogram,
+// so the best key is GetHigh(0); equal scores are ranked by DefaultScorer and
+// then in favour of the smaller key. The plaintexts map keys to decrypted texts.
+// If n is not positive or larger than 256, all keys are returned.
+func FindSingleByteKeyCandidates(input []byte, n int, scorer Scorer) (statistics.ByteScoreHistogram, map[byte][]byte) {
+	if scorer == nil {
+		scorer = DefaultScorer
+	}
+	if n <= 0 || n > 256 {
+		n = 256
+	}
 
-		candidate := string(xor.Single(input, b))
-		scores[b] = GetScore(candidate, ReferenceHistogram)
+	hist := make(statistics.ByteScoreHistogram, 0, 256)
+	for i := 255; i >= 0; i-- {
+		b := byte(i)
+		hist = append(hist, statistics.ByteScoreHistogramEntry{Byte: b, Score: scorer.Score(xor.Single(input, b))})
 	}
+	RankCandidates(hist, scorer, func(b byte) []byte { return xor.Single(input, b) })
+	hist = hist[len(hist)-n:]
 
-	hist := statistics.NewByteScoreHistogram(scores)
-	high := hist.GetHigh(0)
+	plaintexts := make(map[byte][]byte)
+	for _, entry := range hist {
+		plaintexts[entry.Byte] = xor.Single(input, entry.Byte)
+	}
 
-	result := string(xor.Single(input, high.Byte))
+	return hist, plaintexts
+}
 
-	if high.Score < 350.0 {
-		return result, high.Byte, high.Score, fmt.Errorf("%q has score %.f, probably not english", result, high.Score)
+// RankCandidates sorts key candidates that were rated with scorer by ascending
+// score, like statistics.NewByteScoreHistogram. Equal scores are ranked by the
+// DefaultScorer rating of text(b), the plaintext for key b, and then keep their
+// order in hist. The tie-break is only computed for ties, and not at all if
+// scorer already ranks like DefaultScorer.
+func RankCandidates(hist statistics.ByteScoreHistogram, scorer Scorer, text func(b byte) []byte) {
+	var tieBreak [256]statistics.Score
+	var known [256]bool
+	tieScore := func(b byte) statistics.Score {
+		if !known[b] {
+			tieBreak[b], known[b] = DefaultScorer.Score(text(b)), true
+		}
+		return tieBreak[b]
 	}
+	skip := ranksLikeDefault(scorer)
 
-	return result, high.Byte, high.Score, nil
+	sort.SliceStable(hist, func(i, j int) bool {
+		if hist[i].Score != hist[j].Score || skip {
+			return hist[i].Score < hist[j].Score
+		}
+		return tieScore(hist[i].Byte) < tieScore(hist[j].Byte)
+	})
+}
+
+// ranksLikeDefault reports whether scorer, or the Scorer of a *Detector, is
+// the same RuneFrequencyScorer as DefaultScorer
+func ranksLikeDefault(scorer Scorer) bool {
+	if d, ok := scorer.(*Detector); ok {
+		scorer = d.Scorer
+	}
+	s, ok := scorer.(RuneFrequencyScorer)
+	def, isDefault := DefaultScorer.(RuneFrequencyScorer)
+	return ok && isDefault && s.Reference == nil && def.Reference == nil
 }
 
 // EnglishText is a sample modern English text.
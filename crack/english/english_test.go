@@ -1,6 +1,7 @@
 package english
 
 import (
+	"encoding/hex"
 	"testing"
 )
 
@@ -31,3 +32,25 @@ func TestIsEnglish(t *testing.T) {
 		})
 	}
 }
+
+func TestFindSingleByteKeyCandidates(t *testing.T) {
+	input, err := hex.DecodeString("1b37373331363f78151b7f2b783431333d78397828372d363c78373e783a393b3736")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hist, plaintexts := FindSingleByteKeyCandidates(input, 5, nil)
+	if len(hist) != 5 || len(plaintexts) != 5 {
+		t.Fatalf("got %d candidates and %d plaintexts, want 5", len(hist), len(plaintexts))
+	}
+	if best := hist.GetHigh(0).Byte; best != 'X' {
+		t.Errorf("best key = %q, want 'X'", best)
+	}
+	if got, want := string(plaintexts['X']), "Cooking MC's like a pound of bacon"; got != want {
+		t.Errorf("plaintext = %q, want %q", got, want)
+	}
+
+	if all, _ := FindSingleByteKeyCandidates(input, 0, nil); len(all) != 256 {
+		t.Errorf("got %d candidates for n = 0, want 256", len(all))
+	}
+}
@@ -0,0 +1,295 @@
+package english
+
+import (
+	"math"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/Xjs/cryptopals/statistics"
+)
+
+// A Scorer rates how plausible a candidate plaintext is. Higher scores are better.
+type Scorer interface {
+	Score(text []byte) statistics.Score
+}
+
+// A Thresholder is a Scorer that knows the lowest score a plausible plaintext has.
+// FindSingleByteKey and TryKeylen use it to decide whether a decryption looks right.
+type Thresholder interface {
+	Scorer
+	Threshold() statistics.Score
+}
+
+// A Classifier is a Scorer that decides itself whether a text is plausible,
+// for example with thresholds that depend on the length of the text like a
+// Detector. FindSingleByteKey prefers it over Thresholder.
+type Classifier interface {
+	Scorer
+	IsEnglish(text []byte) bool
+}
+
+// A ScorerFunc is an ordinary function used as a Scorer
+type ScorerFunc func(text []byte) statistics.Score
+
+// Score returns f(text)
+func (f ScorerFunc) Score(text []byte) statistics.Score { return f(text) }
+
+// RuneFrequencyScorer scores texts with GetScore against a rune histogram.
+// This is the original scoring of this package, tuned for English prose.
+// Its scores depend on the length of the text, so it has no fixed threshold;
+// DefaultDetector holds thresholds calibrated for it.
+type RuneFrequencyScorer struct {
+	// Reference is the histogram to score against. If nil, ReferenceHistogram is used.
+	Reference map[rune]int
+}
+
+// Score returns GetScore(text, Reference)
+func (s RuneFrequencyScorer) Score(text []byte) statistics.Score {
+	if s.Reference != nil {
+		return GetScore(string(text), s.Reference)
+	}
+
+	// Same as GetScore with ReferenceHistogram, but ASCII runes are looked up in
+	// a table: this scorer rates all 256 keys in FindSingleByteKey
+	var cumulativeFrequency int
+	for i := 0; i < len(text); {
+		if text[i] < utf8.RuneSelf {
+			cumulativeFrequency += referenceASCII[text[i]]
+			i++
+			continue
+		}
+		r, size := utf8.DecodeRune(text[i:])
+		if r == utf8.RuneError {
+			cumulativeFrequency += referenceRuneError
+		} else {
+			cumulativeFrequency += ReferenceHistogram[r]
+		}
+		i += size
+	}
+	return statistics.RelativeScore(cumulativeFrequency, len(text))
+}
+
+// referenceASCII holds the ASCII part of ReferenceHistogram, and
+// referenceRuneError its count of invalid UTF-8
+var (
+	referenceASCII     [utf8.RuneSelf]int
+	referenceRuneError int
+)
+
+// DefaultScorer is used whenever a nil Scorer is passed
+var DefaultScorer Scorer = RuneFrequencyScorer{}
+
+// ChiSquaredScorer compares the case-insensitive letter frequencies of a text with
+// expected frequencies using Pearson's chi-squared test. The score is the negated
+// chi-squared statistic, so that higher is better; texts without letters get -Inf.
+// Letters missing from Expected and runes that are neither letters nor printable
+// ASCII are expected with a small floor frequency, so that they are penalised heavily.
+type ChiSquaredScorer struct {
+	// Expected maps lowercase letters to their relative frequency
+	Expected map[rune]float64
+}
+
+// NewChiSquaredScorer creates a ChiSquaredScorer with letter frequencies taken from reference
+func NewChiSquaredScorer(reference string) ChiSquaredScorer {
+	counts := GetLowerLetterFrequency(reference)
+	var total int
+	for _, c := range counts {
+		total += c
+	}
+	expected := make(map[rune]float64)
+	for r, c := range counts {
+		expected[r] = float64(c) / float64(total)
+	}
+	return ChiSquaredScorer{Expected: expected}
+}
+
+// Score returns the negated chi-squared statistic of text
+func (s ChiSquaredScorer) Score(text []byte) statistics.Score {
+	observed := GetLowerLetterFrequency(string(text))
+	var total int
+	for _, c := range observed {
+		total += c
+	}
+	if total == 0 {
+		return statistics.Score(math.Inf(-1))
+	}
+
+	var chi2 float64
+	for r, p := range s.Expected {
+		e := p * float64(total)
+		d := float64(observed[r]) - e
+		chi2 += d * d / e
+	}
+
+	var unexpected, runes int
+	for _, r := range string(text) {
+		runes++
+		if unicode.IsLetter(r) {
+			if _, ok := s.Expected[unicode.ToLower(r)]; !ok {
+				unexpected++
+			}
+		} else if !isPrintable(r) {
+			unexpected++
+		}
+	}
+	if unexpected > 0 {
+		e := unexpectedFrequency * float64(runes)
+		d := float64(unexpected) - e
+		chi2 += d * d / e
+	}
+
+	return statistics.Score(-chi2)
+}
+
+// unexpectedFrequency is the relative frequency ChiSquaredScorer expects of
+// runes it has no frequency for
+const unexpectedFrequency = 0.001
+
+// isPrintable reports whether r is printable ASCII or common whitespace
+func isPrintable(r rune) bool {
+	return (r >= 0x20 && r < 0x7f) || r == '\n' || r == '\r' || r == '\t'
+}
+
+// NGramScorer scores texts by the average log10-likelihood of their
+// lowercased n-grams (e.g. bigrams or trigrams) in a reference text.
+type NGramScorer struct {
+	N       int
+	LogProb map[string]float64
+	// Floor is the log-likelihood assigned to n-grams absent from LogProb
+	Floor float64
+}
+
+// NewNGramScorer counts all n-grams of length n in reference
+func NewNGramScorer(reference string, n int) NGramScorer {
+	counts := make(map[string]int)
+	runes := []rune(strings.ToLower(reference))
+	var total int
+	for i := 0; i+n <= len(runes); i++ {
+		counts[string(runes[i:i+n])]++
+		total++
+	}
+
+	logProb := make(map[string]float64)
+	for gram, c := range counts {
+		logProb[gram] = math.Log10(float64(c) / float64(total))
+	}
+
+	return NGramScorer{N: n, LogProb: logProb, Floor: math.Log10(0.01 / float64(total))}
+}
+
+// Score returns the average log-likelihood per n-gram of text
+func (s NGramScorer) Score(text []byte) statistics.Score {
+	runes := []rune(strings.ToLower(string(text)))
+	if len(runes) < s.N {
+		return statistics.Score(s.Floor)
+	}
+
+	var sum float64
+	var count int
+	for i := 0; i+s.N <= len(runes); i++ {
+		p, ok := s.LogProb[string(runes[i:i+s.N])]
+		if !ok {
+			p = s.Floor
+		}
+		sum += p
+		count++
+	}
+
+	return statistics.Score(sum / float64(count))
+}
+
+// PrintableScorer scores texts by the fraction of bytes that are printable ASCII
+// or common whitespace. It is suited for plaintexts that are not prose,
+// like JSON, logs or source code.
+type PrintableScorer struct{}
+
+// Score returns the ratio of printable bytes in text
+func (PrintableScorer) Score(text []byte) statistics.Score {
+	if len(text) == 0 {
+		return 0
+	}
+	var printable int
+	for _, b := range text {
+		if isPrintable(rune(b)) {
+			printable++
+		}
+	}
+	return statistics.RelativeScore(printable, len(text))
+}
+
+// Threshold returns the minimum ratio of printable bytes
+func (PrintableScorer) Threshold() statistics.Score { return 0.95 }
+
+// DictionaryScorer scores texts by the fraction of their letters that are
+// part of words found in a dictionary. Single letters are never known, and
+// runes that are neither letters nor printable ASCII count as unknown letters,
+// so that a few lucky matches among garbage do not score well.
+type DictionaryScorer struct {
+	Words map[string]bool
+}
+
+// NewDictionaryScorer creates a DictionaryScorer from a list of words.
+// Words are matched case-insensitively.
+func NewDictionaryScorer(words []string) DictionaryScorer {
+	dict := make(map[string]bool)
+	for _, w := range words {
+		dict[strings.ToLower(w)] = true
+	}
+	return DictionaryScorer{Words: dict}
+}
+
+// Score returns the fraction of letters in text that belong to dictionary words
+func (s DictionaryScorer) Score(text []byte) statistics.Score {
+	var known, letters int
+	for _, r := range string(text) {
+		if !unicode.IsLetter(r) && !isPrintable(r) {
+			letters++
+		}
+	}
+	for _, word := range strings.FieldsFunc(string(text), func(r rune) bool { return !unicode.IsLetter(r) && r != '\'' }) {
+		n := len([]rune(word))
+		letters += n
+		if n > 1 && s.Words[strings.ToLower(strings.Trim(word, "'"))] {
+			known += n
+		}
+	}
+	if letters == 0 {
+		return 0
+	}
+	return statistics.RelativeScore(known, letters)
+}
+
+// Threshold returns the minimum fraction of known letters
+func (DictionaryScorer) Threshold() statistics.Score { return 0.5 }
+
+// CommonWords is a small list of frequent English words
+var CommonWords = strings.Fields(`the be to of and a in that have i it for not on with he as you do at
+this but his by from they we say her she or an will my one all would there their what so up out if
+about who get which go me when make can like time no just him know take people into year your good
+some could them see other than then now look only come its over think also back after use two how
+our work first well way even new want because any these give day most us is are was were been has
+had did said am yes yeah let man men woman down off here where why very more much too again still`)
+
+var (
+	// BigramScorer is an NGramScorer for bigrams of EnglishText
+	BigramScorer = NewNGramScorer(EnglishText, 2)
+	// TrigramScorer is an NGramScorer for trigrams of EnglishText
+	TrigramScorer = NewNGramScorer(EnglishText, 3)
+	// LetterChiSquaredScorer is a ChiSquaredScorer for the letters of EnglishText
+	LetterChiSquaredScorer = NewChiSquaredScorer(EnglishText)
+	// WordScorer is a DictionaryScorer with CommonWords and the words of EnglishText
+	WordScorer = NewDictionaryScorer(append(referenceWords(), CommonWords...))
+)
+
+// referenceWords returns the words of EnglishText with at least three letters.
+// Shorter ones are mostly dialect fragments like "t'" that would match garbage.
+func referenceWords() []string {
+	var result []string
+	for _, word := range strings.FieldsFunc(EnglishText, func(r rune) bool { return !unicode.IsLetter(r) }) {
+		if len([]rune(word)) >= 3 {
+			result = append(result, word)
+		}
+	}
+	return result
+}
@@ -0,0 +1,84 @@
+package english
+
+import (
+	"testing"
+
+	"github.com/Xjs/cryptopals/xor"
+)
+
+func TestScorers(t *testing.T) {
+	scorers := []struct {
+		name   string
+		scorer Scorer
+	}{
+		{"rune-frequency", DefaultScorer},
+		{"chi-squared", LetterChiSquaredScorer},
+		{"bigram", BigramScorer},
+		{"trigram", TrigramScorer},
+		{"printable", PrintableScorer{}},
+		{"dictionary", WordScorer},
+	}
+	good := []byte("The keeper lifted his face and saw that she had come to the little clearing in the wood.")
+	bad := xor.Single(good, 0x5a)
+	for _, tt := range scorers {
+		t.Run(tt.name, func(t *testing.T) {
+			g, b := tt.scorer.Score(good), tt.scorer.Score(bad)
+			if g <= b {
+				t.Errorf("Score(plaintext) = %v <= Score(ciphertext) = %v", g, b)
+			}
+
+			text, key, _, err := FindSingleByteKey(bad, tt.scorer)
+			if err != nil {
+				t.Error(err)
+			}
+			if key != 0x5a || text != string(good) {
+				t.Errorf("FindSingleByteKey() = %q, %q", text, key)
+			}
+		})
+	}
+}
+
+func TestRuneFrequencyScorer(t *testing.T) {
+	for _, text := range []string{"I wish to watch my Irish wristwatch", "Kapitänsmützen", "ごめんなさい！", "\xff\x00\xe4a\xc3"} {
+		want := GetScore(text, ReferenceHistogram)
+		if got := (RuneFrequencyScorer{}).Score([]byte(text)); got != want {
+			t.Errorf("Score(%q) = %v, want %v", text, got, want)
+		}
+		german := GetRuneFrequency("Deutscher Beispieltext")
+		if got := (RuneFrequencyScorer{Reference: german}).Score([]byte(text)); got != GetScore(text, german) {
+			t.Errorf("Score(%q) with reference = %v, want %v", text, got, GetScore(text, german))
+		}
+	}
+}
+
+func TestScorerFunc(t *testing.T) {
+	var s Scorer = ScorerFunc(PrintableScorer{}.Score)
+	if got := s.Score([]byte("ab\x00\x01")); got != 0.5 {
+		t.Errorf("Score() = %v, want 0.5", got)
+	}
+}
+
+func TestFindSingleByteKeyNonProse(t *testing.T) {
+	plaintexts := []string{
+		`{"id":12345,"name":"widget","tags":["a","b"],"price":9.99,"active":true}`,
+		`2024-01-01T00:00:00Z INFO server started on port 8080 pid=4242`,
+		"func main() {\n\tfmt.Println(\"hello, world\")\n}\n",
+	}
+	scorers := []struct {
+		name   string
+		scorer Scorer
+	}{
+		{"printable", PrintableScorer{}},
+		{"bigram", BigramScorer},
+	}
+	for _, tt := range scorers {
+		for _, p := range plaintexts {
+			for _, key := range []byte{0x11, 0x5a, 0xa7} {
+				text, got, _, _ := FindSingleByteKey(xor.Single([]byte(p), key), tt.scorer)
+				if got != key || text != p {
+					t.Errorf("%s: FindSingleByteKey(%q ^ %#x) = %q, %#x", tt.name, p, key, text, got)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,166 @@
+// Package lang provides language models built from reference corpora and
+// detects the language of a text by comparing it against them.
+package lang
+
+import (
+	"embed"
+	"errors"
+	"io"
+	"io/ioutil"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Xjs/cryptopals/crack/english"
+	"github.com/Xjs/cryptopals/statistics"
+)
+
+// unseenLogProb is the log-likelihood of n-grams that do not occur in a corpus.
+// It is the same for all models so that their scores are comparable.
+const unseenLogProb = -6.0
+
+// ErrEmptyCorpus is returned when a model is built from an empty corpus
+var ErrEmptyCorpus = errors.New("lang: empty corpus")
+
+// A Model is a language model derived from a reference corpus
+type Model struct {
+	Name string
+	// Histogram is the rune frequency of the corpus, as used by english.GetScore
+	Histogram map[rune]int
+	Letters   english.ChiSquaredScorer
+	Bigrams   english.NGramScorer
+	Trigrams  english.NGramScorer
+}
+
+// NewModel builds a Model from the given corpus
+func NewModel(name, corpus string) (*Model, error) {
+	if strings.TrimSpace(corpus) == "" {
+		return nil, ErrEmptyCorpus
+	}
+
+	m := &Model{
+		Name:      name,
+		Histogram: english.GetRuneFrequency(corpus),
+		Letters:   english.NewChiSquaredScorer(corpus),
+		Bigrams:   english.NewNGramScorer(corpus, 2),
+		Trigrams:  english.NewNGramScorer(corpus, 3),
+	}
+	m.Bigrams.Floor = unseenLogProb
+	m.Trigrams.Floor = unseenLogProb
+
+	return m, nil
+}
+
+// ReadModel builds a Model from a corpus read from r
+func ReadModel(name string, r io.Reader) (*Model, error) {
+	corpus, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return NewModel(name, string(corpus))
+}
+
+// Score rates how well text fits the model, using trigram log-likelihood.
+// Higher is better. A Model can thus be used as an english.Scorer.
+func (m *Model) Score(text []byte) statistics.Score {
+	return m.Trigrams.Score(text)
+}
+
+// A Registry is a set of language models, identified by name.
+// It is safe for concurrent use.
+type Registry struct {
+	mu     sync.RWMutex
+	models map[string]*Model
+}
+
+// NewRegistry creates an empty Registry
+func NewRegistry() *Registry {
+	return &Registry{models: make(map[string]*Model)}
+}
+
+// Register adds m to the registry, replacing a model of the same name
+func (r *Registry) Register(m *Model) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.models[m.Name] = m
+}
+
+// Load reads a corpus from rd and registers the resulting model under name
+func (r *Registry) Load(name string, rd io.Reader) error {
+	m, err := ReadModel(name, rd)
+	if err != nil {
+		return err
+	}
+	r.Register(m)
+	return nil
+}
+
+// Lookup returns the model registered under name
+func (r *Registry) Lookup(name string) (*Model, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	m, ok := r.models[name]
+	return m, ok
+}
+
+// Names returns the sorted names of all registered models
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var result []string
+	for name := range r.models {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// DetectLanguage scores text against all registered models. The best
+// matching language is GetHigh(0) of the returned histogram.
+func (r *Registry) DetectLanguage(text string) statistics.StringScoreHistogram {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	scores := make(map[string]statistics.Score)
+	for name, m := range r.models {
+		scores[name] = m.Score([]byte(text))
+	}
+	return statistics.NewStringScoreHistogram(scores)
+}
+
+//go:embed corpora/*.txt
+var corpora embed.FS
+
+// Default is the registry used by the package-level functions. It contains a
+// model for every corpus embedded from the corpora directory, named after the
+// file without extension (e.g. "en", "de", "fr").
+var Default = NewRegistry()
+
+func init() {
+	entries, err := corpora.ReadDir("corpora")
+	if err != nil {
+		panic(err)
+	}
+	for _, entry := range entries {
+		f, err := corpora.Open(path.Join("corpora", entry.Name()))
+		if err != nil {
+			panic(err)
+		}
+		err = Default.Load(strings.TrimSuffix(entry.Name(), path.Ext(entry.Name())), f)
+		f.Close()
+		if err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Register adds m to the Default registry
+func Register(m *Model) { Default.Register(m) }
+
+// Lookup returns the model registered under name in the Default registry
+func Lookup(name string) (*Model, bool) { return Default.Lookup(name) }
+
+// DetectLanguage scores text against all models of the Default registry
+func DetectLanguage(text string) statistics.StringScoreHistogram {
+	return Default.DetectLanguage(text)
+}
@@ -0,0 +1,69 @@
+package lang
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/Xjs/cryptopals/crack/english"
+	"github.com/Xjs/cryptopals/xor"
+)
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{"wristwatch", "I wish to watch my Irish wristwatch", "en"},
+		{"chatterley", english.EnglishText, "en"},
+		{"german", "Deutscher Beispieltext, überhaupt nicht englisch, denn er enthält viel mehr Umlaute und Kapitänsmützen und lange Wörter und sowas.", "de"},
+		{"german2", "Ja, ich versuch ihn mal noch ein bißchen ordentlich aufzuschreiben.", "de"},
+		{"french", "Nous sommes allés au marché ce matin pour acheter des légumes et du fromage.", "fr"},
+		{"french2", "Je ne sais pas pourquoi il est parti sans dire au revoir à ses amis.", "fr"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hist := DetectLanguage(tt.text)
+			if got := hist.GetHigh(0).String; got != tt.want {
+				t.Errorf("DetectLanguage() = %v, want %v first", hist, tt.want)
+			}
+		})
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Load("empty", strings.NewReader("  \n")); err != ErrEmptyCorpus {
+		t.Errorf("Load() error = %v, want %v", err, ErrEmptyCorpus)
+	}
+	if err := r.Load("chatterley", strings.NewReader(english.EnglishText)); err != nil {
+		t.Fatal(err)
+	}
+	if names := r.Names(); len(names) != 1 || names[0] != "chatterley" {
+		t.Errorf("Names() = %v", names)
+	}
+	if _, ok := r.Lookup("chatterley"); !ok {
+		t.Error("Lookup() did not find registered model")
+	}
+
+	for _, name := range []string{"en", "de", "fr"} {
+		if _, ok := Lookup(name); !ok {
+			t.Errorf("Default registry lacks %q", name)
+		}
+	}
+}
+
+func TestModelAsScorer(t *testing.T) {
+	de, ok := Lookup("de")
+	if !ok {
+		t.Fatal("no german model")
+	}
+	plaintext := "Der Junge lief über die Brücke und rief, dass das Wasser im Fluss schon sehr hoch stehe."
+	text, key, _, err := english.FindSingleByteKey(xor.Single([]byte(plaintext), 0x2a), de)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key != 0x2a || text != plaintext {
+		t.Errorf("FindSingleByteKey() = %q, %q", text, key)
+	}
+}
@@ -0,0 +1,142 @@
+// Package lengthext implements length extension attacks against secret-prefix
+// MACs, H(secret || message), built on Merkle–Damgård hashes.
+package lengthext
+
+import (
+	"errors"
+	"hash"
+
+	"github.com/Xjs/cryptopals/hashes/md4"
+	"github.com/Xjs/cryptopals/hashes/md5"
+	"github.com/Xjs/cryptopals/hashes/sha1"
+	"github.com/Xjs/cryptopals/hashes/sha256"
+)
+
+var (
+	// ErrSecretLengthRange is returned if the range of guessed secret lengths is empty or negative
+	ErrSecretLengthRange = errors.New("lengthext: invalid range of secret lengths")
+	// ErrNoForgery is returned if no guessed secret length yields a valid forgery
+	ErrNoForgery = errors.New("lengthext: no forgery verified")
+)
+
+// A Resumable is a Merkle–Damgård hash whose computation can be resumed from a digest
+type Resumable interface {
+	// New returns a hash in its initial state
+	New() hash.Hash
+	// Resume returns a hash continuing from the state that produced digest,
+	// after length bytes have been processed
+	Resume(digest []byte, length uint64) (hash.Hash, error)
+	// Padding returns the padding the hash appends to a message of messageLen bytes
+	Padding(messageLen uint64) []byte
+}
+
+// A ResumableFuncs is a Resumable made of ordinary functions
+type ResumableFuncs struct {
+	NewFunc     func() hash.Hash
+	ResumeFunc  func(digest []byte, length uint64) (hash.Hash, error)
+	PaddingFunc func(messageLen uint64) []byte
+}
+
+// New returns r.NewFunc()
+func (r ResumableFuncs) New() hash.Hash { return r.NewFunc() }
+
+// Resume returns r.ResumeFunc(digest, length)
+func (r ResumableFuncs) Resume(digest []byte, length uint64) (hash.Hash, error) {
+	return r.ResumeFunc(digest, length)
+}
+
+// Padding returns r.PaddingFunc(messageLen)
+func (r ResumableFuncs) Padding(messageLen uint64) []byte { return r.PaddingFunc(messageLen) }
+
+// Resumable implementations of the hashes in this repository
+var (
+	SHA1 Resumable = ResumableFuncs{
+		NewFunc: func() hash.Hash { return sha1.New() },
+		ResumeFunc: func(digest []byte, length uint64) (hash.Hash, error) {
+			return sha1.NewFromDigest(digest, length)
+		},
+		PaddingFunc: sha1.MDPadding,
+	}
+	SHA256 Resumable = ResumableFuncs{
+		NewFunc: func() hash.Hash { return sha256.New() },
+		ResumeFunc: func(digest []byte, length uint64) (hash.Hash, error) {
+			return sha256.NewFromDigest(digest, length)
+		},
+		PaddingFunc: sha256.MDPadding,
+	}
+	MD5 Resumable = ResumableFuncs{
+		NewFunc: func() hash.Hash { return md5.New() },
+		ResumeFunc: func(digest []byte, length uint64) (hash.Hash, error) {
+			return md5.NewFromDigest(digest, length)
+		},
+		PaddingFunc: md5.MDPadding,
+	}
+	MD4 Resumable = ResumableFuncs{
+		NewFunc: func() hash.Hash { return md4.New() },
+		ResumeFunc: func(digest []byte, length uint64) (hash.Hash, error) {
+			return md4.NewFromDigest(digest, length)
+		},
+		PaddingFunc: md4.MDPadding,
+	}
+)
+
+// A Forgery is a message and its MAC, valid if the secret has SecretLen bytes
+type Forgery struct {
+	SecretLen int
+	Message   []byte
+	MAC       []byte
+}
+
+// Extend forges the MAC of message || padding || suffix from the MAC of
+// message, for a secret of secretLen bytes
+func Extend(h Resumable, mac, message, suffix []byte, secretLen int) (*Forgery, error) {
+	if secretLen < 0 {
+		return nil, ErrSecretLengthRange
+	}
+
+	glue := h.Padding(uint64(secretLen + len(message)))
+	forged := make([]byte, 0, len(message)+len(glue)+len(suffix))
+	forged = append(forged, message...)
+	forged = append(forged, glue...)
+	forged = append(forged, suffix...)
+
+	resumed, err := h.Resume(mac, uint64(secretLen+len(message)+len(glue)))
+	if err != nil {
+		return nil, err
+	}
+	resumed.Write(suffix)
+
+	return &Forgery{SecretLen: secretLen, Message: forged, MAC: resumed.Sum(nil)}, nil
+}
+
+// Forge returns one Forgery for each guessed secret length from minSecretLen
+// to maxSecretLen (inclusive)
+func Forge(h Resumable, mac, message, suffix []byte, minSecretLen, maxSecretLen int) ([]Forgery, error) {
+	if minSecretLen < 0 || maxSecretLen < minSecretLen {
+		return nil, ErrSecretLengthRange
+	}
+
+	result := make([]Forgery, 0, maxSecretLen-minSecretLen+1)
+	for n := minSecretLen; n <= maxSecretLen; n++ {
+		f, err := Extend(h, mac, message, suffix, n)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, *f)
+	}
+	return result, nil
+}
+
+// Find returns the first Forgery from Forge that verify accepts
+func Find(h Resumable, mac, message, suffix []byte, minSecretLen, maxSecretLen int, verify func(message, mac []byte) bool) (*Forgery, error) {
+	forgeries, err := Forge(h, mac, message, suffix, minSecretLen, maxSecretLen)
+	if err != nil {
+		return nil, err
+	}
+	for i := range forgeries {
+		if verify(forgeries[i].Message, forgeries[i].MAC) {
+			return &forgeries[i], nil
+		}
+	}
+	return nil, ErrNoForgery
+}
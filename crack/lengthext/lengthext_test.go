@@ -0,0 +1,71 @@
+package lengthext
+
+import (
+	"bytes"
+	"math/rand"
+	"testing"
+
+	"github.com/Xjs/cryptopals/oracle"
+)
+
+const (
+	message = "comment1=cooking%20MCs;userdata=foo;comment2=%20like%20a%20pound%20of%20bacon"
+	suffix  = ";admin=true"
+)
+
+// TestFind covers challenges 29 and 30 and their SHA-256 and MD5 counterparts
+func TestFind(t *testing.T) {
+	tests := []struct {
+		name string
+		h    Resumable
+	}{
+		{"sha1", SHA1},
+		{"md4", MD4},
+		{"sha256", SHA256},
+		{"md5", MD5},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, err := oracle.NewSecretPrefixMAC(rand.New(rand.NewSource(int64(29+i))), tt.h.New, 32)
+			if err != nil {
+				t.Fatal(err)
+			}
+			mac := o.Sign([]byte(message))
+
+			f, err := Find(tt.h, mac, []byte(message), []byte(suffix), 0, 32, o.Verify)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.HasPrefix(f.Message, []byte(message)) || !bytes.HasSuffix(f.Message, []byte(suffix)) {
+				t.Errorf("forged message %q does not extend the original", f.Message)
+			}
+			if !bytes.Equal(o.Sign(f.Message), f.MAC) {
+				t.Errorf("forged MAC %x is invalid", f.MAC)
+			}
+		})
+	}
+}
+
+func TestFindErrors(t *testing.T) {
+	o, err := oracle.NewSecretPrefixMAC(rand.New(rand.NewSource(28)), SHA1.New, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mac := o.Sign([]byte(message))
+
+	tests := []struct {
+		name     string
+		mac      []byte
+		min, max int
+		want     error
+	}{
+		{"range", mac, 5, 4, ErrSecretLengthRange},
+		{"negative", mac, -1, 4, ErrSecretLengthRange},
+		{"wrong-mac", make([]byte, len(mac)), 0, 16, ErrNoForgery},
+	}
+	for _, tt := range tests {
+		if _, err := Find(SHA1, tt.mac, []byte(message), []byte(suffix), tt.min, tt.max, o.Verify); err != tt.want {
+			t.Errorf("%s: Find() error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+}
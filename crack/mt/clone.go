@@ -0,0 +1,53 @@
+// Package mt implements attacks against the Mersenne Twister MT19937.
+package mt
+
+import (
+	"errors"
+
+	"github.com/Xjs/cryptopals/mt"
+)
+
+// ErrTooFewOutputs is returned if fewer than mt.N outputs are given to Clone
+var ErrTooFewOutputs = errors.New("mt: need at least 624 outputs")
+
+// undoRightShift inverts y ^= y >> shift
+func undoRightShift(y uint32, shift uint) uint32 {
+	result := y
+	for i := uint(0); i < 32; i += shift {
+		result = y ^ result>>shift
+	}
+	return result
+}
+
+// undoLeftShift inverts y ^= (y << shift) & mask
+func undoLeftShift(y uint32, shift uint, mask uint32) uint32 {
+	result := y
+	for i := uint(0); i < 32; i += shift {
+		result = y ^ (result<<shift)&mask
+	}
+	return result
+}
+
+// Untemper inverts mt.Temper, recovering a word of MT19937 state from an output
+func Untemper(y uint32) uint32 {
+	y = undoRightShift(y, mt.L)
+	y = undoLeftShift(y, mt.T, mt.C)
+	y = undoLeftShift(y, mt.S, mt.B)
+	y = undoRightShift(y, mt.U)
+	return y
+}
+
+// Clone recovers the state of an MT19937 generator from consecutive outputs.
+// Only the last mt.N outputs are used. The returned generator continues where
+// the observed outputs end.
+func Clone(outputs []uint32) (*mt.MT19937, error) {
+	if len(outputs) < mt.N {
+		return nil, ErrTooFewOutputs
+	}
+
+	var state [mt.N]uint32
+	for i, y := range outputs[len(outputs)-mt.N:] {
+		state[i] = Untemper(y)
+	}
+	return mt.NewFromState(state, mt.N), nil
+}
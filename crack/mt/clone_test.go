@@ -0,0 +1,92 @@
+package mt
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/Xjs/cryptopals/mt"
+)
+
+func TestUntemper(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 10000; i++ {
+		y := r.Uint32()
+		if got := Untemper(mt.Temper(y)); got != y {
+			t.Fatalf("Untemper(Temper(%#x)) = %#x", y, got)
+		}
+	}
+}
+
+// TestClone is challenge 23
+func TestClone(t *testing.T) {
+	original := mt.New(uint32(rand.Int63()))
+	// Start somewhere in the middle of a twist period
+	for i := 0; i < 100; i++ {
+		original.Uint32()
+	}
+
+	outputs := make([]uint32, mt.N)
+	for i := range outputs {
+		outputs[i] = original.Uint32()
+	}
+
+	clone, err := Clone(outputs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2000; i++ {
+		if got, want := clone.Uint32(), original.Uint32(); got != want {
+			t.Fatalf("prediction %d = %d, want %d", i, got, want)
+		}
+	}
+
+	if _, err := Clone(outputs[1:]); err != ErrTooFewOutputs {
+		t.Errorf("Clone() error = %v, want %v", err, ErrTooFewOutputs)
+	}
+}
+
+func TestCloneTruncated(t *testing.T) {
+	tests := []struct {
+		name    string
+		outputs int
+		observe func(uint32) Observation
+		wantErr error
+	}{
+		{"full", mt.N + 1, func(y uint32) Observation { return Observation{y, ^uint32(0)} }, nil},
+		{"top-16-bits", 1300, func(y uint32) Observation { return TopBits([]uint32{y >> 16}, 16)[0] }, nil},
+		{"low-byte", 4000, func(y uint32) Observation { return Observation{y & 0xff, 0xff} }, nil},
+		{"too-few", 1000, func(y uint32) Observation { return Observation{y & 0xff, 0xff} }, ErrUnderdetermined},
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			original := mt.New(uint32(1000 + i))
+			observations := make([]Observation, tt.outputs)
+			for i := range observations {
+				observations[i] = tt.observe(original.Uint32())
+			}
+
+			clone, err := CloneTruncated(observations)
+			if err != tt.wantErr {
+				t.Fatalf("CloneTruncated() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			for i := 0; i < 1000; i++ {
+				if got, want := clone.Uint32(), original.Uint32(); got != want {
+					t.Fatalf("prediction %d = %d, want %d", i, got, want)
+				}
+			}
+		})
+	}
+
+	original := mt.New(5)
+	observations := make([]Observation, 700)
+	for i := range observations {
+		observations[i] = Observation{original.Uint32(), ^uint32(0)}
+	}
+	observations[650].Value ^= 1
+	if _, err := CloneTruncated(observations); err != ErrInconsistent {
+		t.Errorf("CloneTruncated() error = %v, want %v", err, ErrInconsistent)
+	}
+}
@@ -0,0 +1,205 @@
+package mt
+
+import (
+	"errors"
+	"math/bits"
+
+	"github.com/Xjs/cryptopals/mt"
+)
+
+var (
+	// ErrUnderdetermined is returned if the observations do not determine the state
+	ErrUnderdetermined = errors.New("mt: observations do not determine the state")
+	// ErrInconsistent is returned if no MT19937 state can produce the observations
+	ErrInconsistent = errors.New("mt: observations are inconsistent")
+)
+
+// An Observation is a partially observed output: only the bits set in Mask
+// are known, and given in Value. An Observation with Mask 0 stands for an
+// output that was skipped.
+type Observation struct {
+	Value, Mask uint32
+}
+
+// TopBits creates observations from outputs of which only the top n bits
+// were seen, given right-aligned like Uint32() >> (32 - n)
+func TopBits(outputs []uint32, n uint) []Observation {
+	result := make([]Observation, len(outputs))
+	for i, o := range outputs {
+		result[i] = Observation{Value: o << (32 - n), Mask: ^uint32(0) << (32 - n)}
+	}
+	return result
+}
+
+// unknowns is the number of state bits the solver works with
+const unknowns = mt.N * 32
+
+// rowWords is the number of 64-bit words of a row
+const rowWords = (unknowns + 63) / 64
+
+// A row is a linear combination of the unknown state bits
+type row []uint64
+
+func newRow() row { return make(row, rowWords) }
+
+func unitRow(bit int) row {
+	r := newRow()
+	r[bit/64] |= 1 << uint(bit%64)
+	return r
+}
+
+func (r row) xor(other row, from int) {
+	for i := from; i < rowWords; i++ {
+		r[i] ^= other[i]
+	}
+}
+
+// A symbolicWord holds the linear combination of unknowns making up each bit of a state word
+type symbolicWord [32]row
+
+// temperMatrix[j] is the set of input bits that output bit j of mt.Temper depends on
+var temperMatrix [32]uint32
+
+func init() {
+	for k := uint(0); k < 32; k++ {
+		out := mt.Temper(1 << k)
+		for j := uint(0); j < 32; j++ {
+			if out&(1<<j) != 0 {
+				temperMatrix[j] |= 1 << k
+			}
+		}
+	}
+}
+
+// twistWord computes the next state word w[i] from w[i-624], w[i-623] and w[i-227]
+func twistWord(first, second, middle *symbolicWord) *symbolicWord {
+	// y = upper bit of first | lower 31 bits of second
+	var y symbolicWord
+	y[31] = first[31]
+	for j := 0; j < 31; j++ {
+		y[j] = second[j]
+	}
+
+	// result = middle ^ (y >> 1) ^ (y & 1 ? MatrixA : 0)
+	var result symbolicWord
+	for j := 0; j < 32; j++ {
+		r := newRow()
+		copy(r, middle[j])
+		if j < 31 {
+			r.xor(y[j+1], 0)
+		}
+		if mt.MatrixA&(1<<uint(j)) != 0 {
+			r.xor(y[0], 0)
+		}
+		result[j] = r
+	}
+	return &result
+}
+
+// A system is a set of linear equations over GF(2), kept in echelon form
+type system struct {
+	pivots [unknowns]row
+	rhs    [unknowns]bool
+}
+
+// add reduces the equation r = rhs against the pivots and adds it if it is
+// independent. It returns false if the equation contradicts the system.
+func (s *system) add(r row, rhs bool) bool {
+	for w := 0; w < rowWords; {
+		if r[w] == 0 {
+			w++
+			continue
+		}
+		bit := w*64 + bits.TrailingZeros64(r[w])
+		if s.pivots[bit] == nil {
+			s.pivots[bit] = r
+			s.rhs[bit] = rhs
+			return true
+		}
+		r.xor(s.pivots[bit], w)
+		rhs = rhs != s.rhs[bit]
+	}
+	return !rhs
+}
+
+// solve back-substitutes the pivots. Bits without pivot are set to zero,
+// except that ErrUnderdetermined is returned if one of them matters.
+func (s *system) solve(irrelevant func(bit int) bool) ([]uint64, error) {
+	values := newRow()
+	for bit := unknowns - 1; bit >= 0; bit-- {
+		p := s.pivots[bit]
+		if p == nil {
+			if !irrelevant(bit) {
+				return nil, ErrUnderdetermined
+			}
+			continue
+		}
+		parity := s.rhs[bit]
+		for w := bit / 64; w < rowWords; w++ {
+			parity = parity != (bits.OnesCount64(p[w]&values[w])%2 == 1)
+		}
+		if parity {
+			values[bit/64] |= 1 << uint(bit%64)
+		}
+	}
+	return values, nil
+}
+
+// CloneTruncated recovers the state of an MT19937 generator from consecutive,
+// partially observed outputs by solving a system of linear equations over GF(2):
+// every output bit is a linear function of the 19968 state bits. Somewhat more
+// than 19937 observed bits are needed, e.g. the top 16 bits of 1300 outputs.
+// The low bits of MT19937 carry less information: with only the lowest byte
+// observed, close to 4000 outputs are needed. The returned generator continues
+// where the observations end.
+func CloneTruncated(observations []Observation) (*mt.MT19937, error) {
+	// The unknowns are the words w[0..623] that are tempered for the first 624
+	// outputs. Later words follow from the twist recurrence.
+	window := make([]*symbolicWord, mt.N)
+	for i := range window {
+		var w symbolicWord
+		for j := range w {
+			w[j] = unitRow(i*32 + j)
+		}
+		window[i] = &w
+	}
+
+	s := &system{}
+	for i, o := range observations {
+		if i >= mt.N {
+			k := i % mt.N
+			window[k] = twistWord(window[k], window[(k+1)%mt.N], window[(k+mt.M)%mt.N])
+		}
+		w := window[i%mt.N]
+		for j := uint(0); j < 32; j++ {
+			if o.Mask&(1<<j) == 0 {
+				continue
+			}
+			r := newRow()
+			for k := uint(0); k < 32; k++ {
+				if temperMatrix[j]&(1<<k) != 0 {
+					r.xor(w[k], 0)
+				}
+			}
+			if !s.add(r, o.Value&(1<<j) != 0) {
+				return nil, ErrInconsistent
+			}
+		}
+	}
+
+	// Only the top bit of w[0] affects later outputs
+	values, err := s.solve(func(bit int) bool { return bit < 31 })
+	if err != nil {
+		return nil, err
+	}
+
+	var state [mt.N]uint32
+	for i := range state {
+		state[i] = uint32(values[i/2] >> (32 * uint(i%2)))
+	}
+	m := mt.NewFromState(state, 0)
+	for range observations {
+		m.Uint32()
+	}
+	return m, nil
+}
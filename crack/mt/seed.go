@@ -0,0 +1,129 @@
+package mt
+
+import (
+	"context"
+	"runtime"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/Xjs/cryptopals/mt"
+)
+
+// checkInterval is the number of seeds a worker tries between checks for cancellation
+const checkInterval = 1024
+
+// A SeedMatcher reports whether a freshly seeded generator could have produced
+// the observed data
+type SeedMatcher func(m *mt.MT19937) bool
+
+// FindSeeds tries all seeds from first to last (inclusive) on all cores and
+// returns the ones for which match returns true, in ascending order. If ctx is
+// cancelled, the search stops and the seeds found so far are returned along
+// with the context's error.
+func FindSeeds(ctx context.Context, first, last uint32, match SeedMatcher) ([]uint32, error) {
+	if last < first {
+		return nil, nil
+	}
+
+	workers := runtime.NumCPU()
+	total := uint64(last-first) + 1
+	chunk := (total + uint64(workers) - 1) / uint64(workers)
+
+	var mu sync.Mutex
+	var result []uint32
+	var wg sync.WaitGroup
+	for w := uint64(0); w < total; w += chunk {
+		from := uint64(first) + w
+		to := from + chunk - 1
+		if to > uint64(last) {
+			to = uint64(last)
+		}
+
+		wg.Add(1)
+		go func(from, to uint64) {
+			defer wg.Done()
+			m := &mt.MT19937{}
+			for seed := from; seed <= to; seed++ {
+				if (seed-from)%checkInterval == 0 && ctx.Err() != nil {
+					return
+				}
+				m.SeedUint32(uint32(seed))
+				if match(m) {
+					mu.Lock()
+					result = append(result, uint32(seed))
+					mu.Unlock()
+				}
+			}
+		}(from, to)
+	}
+	wg.Wait()
+
+	sort.Slice(result, func(a, b int) bool { return result[a] < result[b] })
+	return result, ctx.Err()
+}
+
+// MatchOutputs returns a SeedMatcher for generators whose first outputs are outputs
+func MatchOutputs(outputs []uint32) SeedMatcher {
+	return func(m *mt.MT19937) bool {
+		for _, o := range outputs {
+			if m.Uint32() != o {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// RecoverTimestampSeed finds the seeds between the Unix timestamps of from and
+// to that produce outputs as first outputs (challenge 22)
+func RecoverTimestampSeed(ctx context.Context, outputs []uint32, from, to time.Time) ([]uint32, error) {
+	return FindSeeds(ctx, uint32(from.Unix()), uint32(to.Unix()), MatchOutputs(outputs))
+}
+
+// MatchKeyStream returns a SeedMatcher for generators that, used as an
+// mt.Stream, produce keystream at the given offset
+func MatchKeyStream(keystream []byte, offset int) SeedMatcher {
+	return func(m *mt.MT19937) bool {
+		for i := 0; i < offset; i++ {
+			m.Uint32()
+		}
+		for _, k := range keystream {
+			if byte(m.Uint32()) != k {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// RecoverStreamSeed finds the 16-bit keys of mt.Stream ciphertexts whose
+// plaintext is known to end with knownSuffix (challenge 24)
+func RecoverStreamSeed(ctx context.Context, ciphertext, knownSuffix []byte) ([]uint16, error) {
+	if len(knownSuffix) > len(ciphertext) {
+		knownSuffix = knownSuffix[len(knownSuffix)-len(ciphertext):]
+	}
+	offset := len(ciphertext) - len(knownSuffix)
+	keystream := make([]byte, len(knownSuffix))
+	for i, b := range knownSuffix {
+		keystream[i] = ciphertext[offset+i] ^ b
+	}
+
+	seeds, err := FindSeeds(ctx, 0, 0xffff, MatchKeyStream(keystream, offset))
+	result := make([]uint16, len(seeds))
+	for i, s := range seeds {
+		result[i] = uint16(s)
+	}
+	return result, err
+}
+
+// IsTimeSeededToken checks whether token is the key stream of an mt.Stream
+// seeded with a Unix timestamp between from and to, as an insecure password
+// reset token would be. It returns the timestamp if so.
+func IsTimeSeededToken(ctx context.Context, token []byte, from, to time.Time) (bool, uint32, error) {
+	seeds, err := FindSeeds(ctx, uint32(from.Unix()), uint32(to.Unix()), MatchKeyStream(token, 0))
+	if err != nil || len(seeds) == 0 {
+		return false, 0, err
+	}
+	return true, seeds[0], nil
+}
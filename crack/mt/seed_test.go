@@ -0,0 +1,88 @@
+package mt
+
+import (
+	"bytes"
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/Xjs/cryptopals/mt"
+)
+
+// TestRecoverTimestampSeed is challenge 22
+func TestRecoverTimestampSeed(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	seeded := now.Add(-time.Duration(40+rand.Intn(1000)) * time.Second)
+	m := mt.New(uint32(seeded.Unix()))
+
+	seeds, err := RecoverTimestampSeed(context.Background(), []uint32{m.Uint32()}, now.Add(-time.Hour), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(seeds) != 1 || seeds[0] != uint32(seeded.Unix()) {
+		t.Errorf("RecoverTimestampSeed = %v, want [%d]", seeds, seeded.Unix())
+	}
+}
+
+func TestFindSeedsCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := FindSeeds(ctx, 0, 1<<30, MatchOutputs([]uint32{0})); err != context.Canceled {
+		t.Errorf("FindSeeds with cancelled context: err = %v, want %v", err, context.Canceled)
+	}
+}
+
+// TestRecoverStreamSeed is challenge 24
+func TestRecoverStreamSeed(t *testing.T) {
+	r := rand.New(rand.NewSource(24))
+	key := uint16(r.Intn(1 << 16))
+	known := bytes.Repeat([]byte{'A'}, 14)
+
+	plaintext := make([]byte, 5+r.Intn(20))
+	r.Read(plaintext)
+	plaintext = append(plaintext, known...)
+	ciphertext := make([]byte, len(plaintext))
+	mt.NewStream(uint32(key)).XORKeyStream(ciphertext, plaintext)
+
+	keys, err := RecoverStreamSeed(context.Background(), ciphertext, known)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(keys) != 1 || keys[0] != key {
+		t.Fatalf("RecoverStreamSeed = %v, want [%d]", keys, key)
+	}
+
+	decrypted := make([]byte, len(ciphertext))
+	mt.NewStream(uint32(keys[0])).XORKeyStream(decrypted, ciphertext)
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Errorf("decrypted %q, want %q", decrypted, plaintext)
+	}
+}
+
+func TestIsTimeSeededToken(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	token := make([]byte, 16)
+	mt.NewStream(uint32(now.Add(-5*time.Minute).Unix())).XORKeyStream(token, token)
+
+	tests := []struct {
+		name  string
+		token []byte
+		want  bool
+	}{
+		{"time-seeded", token, true},
+		{"random", []byte("0123456789abcdef"), false},
+	}
+	for _, tt := range tests {
+		got, seed, err := IsTimeSeededToken(context.Background(), tt.token, now.Add(-time.Hour), now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: IsTimeSeededToken = %v, want %v", tt.name, got, tt.want)
+		}
+		if got && seed != uint32(now.Add(-5*time.Minute).Unix()) {
+			t.Errorf("%s: seed = %d", tt.name, seed)
+		}
+	}
+}
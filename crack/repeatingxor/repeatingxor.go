@@ -0,0 +1,196 @@
+// Package repeatingxor breaks repeating-key XOR encryption (Vigenère-style XOR)
+// by guessing the key size and solving each key byte as single-byte XOR.
+package repeatingxor
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+
+	"github.com/Xjs/cryptopals/crack/english"
+	"github.com/Xjs/cryptopals/sliceops"
+	"github.com/Xjs/cryptopals/statistics"
+	"github.com/Xjs/cryptopals/xor"
+)
+
+// ErrInputTooShort is returned if the input does not contain at least
+// two blocks of the key size in question
+var ErrInputTooShort = errors.New("repeatingxor: input too short")
+
+// Options configure BreakRepeatingKeyXOR. The zero value is usable.
+type Options struct {
+	// MinKeySize and MaxKeySize bound the key sizes that are tried (inclusive).
+	// They default to 2 and 40.
+	MinKeySize, MaxKeySize int
+	// KeySizes is the number of best-scoring key sizes for which a key is
+	// recovered. Defaults to 3.
+	KeySizes int
+	// MaxBlocks limits how many leading blocks are compared when scoring a
+	// key size. If it is not positive, all blocks are used; since all pairs
+	// are compared, the work grows with the square of the input length.
+	MaxBlocks int
+	// Scorer rates the decrypted columns. If nil, english.FindSingleByteKey
+	// ranks them like english.DefaultScorer and rejects them with the
+	// thresholds of english.DefaultDetector.
+	Scorer english.Scorer
+}
+
+func (o *Options) withDefaults() Options {
+	var result Options
+	if o != nil {
+		result = *o
+	}
+	if result.MinKeySize <= 0 {
+		result.MinKeySize = 2
+	}
+	if result.MaxKeySize <= 0 {
+		result.MaxKeySize = 40
+	}
+	if result.KeySizes <= 0 {
+		result.KeySizes = 3
+	}
+	return result
+}
+
+// A Candidate is a possible solution found by BreakRepeatingKeyXOR
+type Candidate struct {
+	// Key is the recovered key; its length is the guessed key size.
+	Key       []byte
+	Plaintext []byte
+	// KeySizeScore is the normalised Hamming distance of the key size; lower is better.
+	KeySizeScore statistics.Score
+	// Confidence is the fraction of key bytes whose decrypted column looks English.
+	Confidence statistics.Score
+}
+
+// Candidates is a list of Candidate. It sorts by descending confidence and,
+// for equal confidence, ascending key size score.
+type Candidates []Candidate
+
+func (c Candidates) Less(a, b int) bool {
+	if c[a].Confidence != c[b].Confidence {
+		return c[a].Confidence > c[b].Confidence
+	}
+	return c[a].KeySizeScore < c[b].KeySizeScore
+}
+func (c Candidates) Swap(a, b int) { c[a], c[b] = c[b], c[a] }
+func (c Candidates) Len() int      { return len(c) }
+
+// KeySizeScore returns the Hamming distance between blocks of keysize bytes,
+// normalised by keysize and averaged over all pairs of the first maxBlocks
+// blocks (all blocks if maxBlocks is not positive). The correct key size tends to have
+// the lowest score.
+func KeySizeScore(input []byte, keysize, maxBlocks int) (statistics.Score, error) {
+	blocks := len(input) / keysize
+	if maxBlocks > 0 && blocks > maxBlocks {
+		blocks = maxBlocks
+	}
+	if blocks < 2 {
+		return 0.0, ErrInputTooShort
+	}
+
+	var distance, pairs int
+	for i := 0; i < blocks; i++ {
+		a := input[i*keysize : (i+1)*keysize]
+		for j := i + 1; j < blocks; j++ {
+			b := input[j*keysize : (j+1)*keysize]
+			distance += sliceops.HammingDistance(a, b)
+			pairs++
+		}
+	}
+
+	return statistics.RelativeScore(distance, pairs*keysize), nil
+}
+
+// KeySizeHistogram scores all key sizes between opts.MinKeySize and opts.MaxKeySize.
+// Key sizes for which the input is too short are left out.
+func KeySizeHistogram(input []byte, opts *Options) statistics.SizeScoreHistogram {
+	o := opts.withDefaults()
+
+	scores := make(map[int]statistics.Score)
+	for keysize := o.MinKeySize; keysize <= o.MaxKeySize; keysize++ {
+		score, err := KeySizeScore(input, keysize, o.MaxBlocks)
+		if err != nil {
+			continue
+		}
+		scores[keysize] = score
+	}
+
+	return statistics.NewSizeScoreHistogram(scores)
+}
+
+// RecoverKey recovers a key of the given size by splitting the input into
+// keysize columns and solving each of them with english.FindSingleByteKey
+// and the given scorer, which may be nil.
+// It returns the key and the number of columns that did not look English.
+func RecoverKey(input []byte, keysize int, scorer english.Scorer) ([]byte, int) {
+	columns := make([][]byte, keysize)
+	for i, b := range input {
+		columns[i%keysize] = append(columns[i%keysize], b)
+	}
+
+	key := make([]byte, keysize)
+	var nonEnglish int
+	for i, column := range columns {
+		if len(column) == 0 {
+			nonEnglish++
+			continue
+		}
+		_, k, _, err := english.FindSingleByteKey(column, scorer)
+		if err != nil {
+			nonEnglish++
+		}
+		key[i] = k
+	}
+
+	return key, nonEnglish
+}
+
+// BreakRepeatingKeyXOR attempts to decrypt ciphertext that was encrypted with
+// repeating-key XOR. It ranks key sizes by KeySizeScore, recovers a key for
+// the opts.KeySizes best ones and returns the resulting candidates, best first.
+// opts may be nil.
+func BreakRepeatingKeyXOR(ciphertext []byte, opts *Options) (Candidates, error) {
+	o := opts.withDefaults()
+
+	hist := KeySizeHistogram(ciphertext, &o)
+	if len(hist) == 0 {
+		return nil, ErrInputTooShort
+	}
+
+	var result Candidates
+	seen := make(map[string]bool)
+	for i := 0; i < len(hist) && i < o.KeySizes; i++ {
+		entry := hist[i]
+		key, nonEnglish := RecoverKey(ciphertext, entry.Size, o.Scorer)
+		// Multiples of the real key size yield the key repeated several times
+		key = shortestPeriod(key)
+		if seen[string(key)] {
+			continue
+		}
+		seen[string(key)] = true
+
+		result = append(result, Candidate{
+			Key:          key,
+			Plaintext:    xor.Encrypt(ciphertext, key),
+			KeySizeScore: entry.Score,
+			Confidence:   statistics.RelativeScore(entry.Size-nonEnglish, entry.Size),
+		})
+	}
+
+	sort.Stable(result)
+	return result, nil
+}
+
+// shortestPeriod returns the shortest prefix of key that key is a repetition of
+func shortestPeriod(key []byte) []byte {
+	for size := 1; size < len(key); size++ {
+		if len(key)%size != 0 {
+			continue
+		}
+		if bytes.Equal(key[size:], key[:len(key)-size]) {
+			return key[:size]
+		}
+	}
+	return key
+}
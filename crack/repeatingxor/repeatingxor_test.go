@@ -0,0 +1,59 @@
+package repeatingxor
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/Xjs/cryptopals/crack/english"
+	"github.com/Xjs/cryptopals/xor"
+)
+
+func TestKeySizeScore(t *testing.T) {
+	if _, err := KeySizeScore([]byte("short"), 3, 0); err != ErrInputTooShort {
+		t.Errorf("KeySizeScore() error = %v, want %v", err, ErrInputTooShort)
+	}
+
+	score, err := KeySizeScore([]byte("this is a testwokka wokka!!!"), 14, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 37.0 / 14.0; float64(score) != want {
+		t.Errorf("KeySizeScore() = %v, want %v", score, want)
+	}
+}
+
+func TestBreakRepeatingKeyXOR(t *testing.T) {
+	tests := []struct {
+		name string
+		key  string
+		// repeat is how many copies of EnglishText are encrypted
+		repeat    int
+		maxBlocks int
+	}{
+		{"ice", "ICE", 2, 0},
+		{"short", "Go", 2, 0},
+		{"long", "Frankfurt meetup", 2, 0},
+		// 230 KB, which only stays fast with a limit on the compared blocks
+		{"large", "YELLOW SUBMARINE", 80, 64},
+	}
+	for _, tt := range tests {
+		plaintext := []byte(strings.Repeat(english.EnglishText, tt.repeat))
+		t.Run(tt.name, func(t *testing.T) {
+			ciphertext := xor.Encrypt(plaintext, []byte(tt.key))
+			got, err := BreakRepeatingKeyXOR(ciphertext, &Options{MaxBlocks: tt.maxBlocks})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(got) == 0 {
+				t.Fatal("no candidates")
+			}
+			if string(got[0].Key) != tt.key {
+				t.Errorf("BreakRepeatingKeyXOR() key = %q, want %q (candidates: %d)", got[0].Key, tt.key, len(got))
+			}
+			if !bytes.Equal(got[0].Plaintext, plaintext) {
+				t.Error("BreakRepeatingKeyXOR() did not recover the plaintext")
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"crypto/aes"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"log"
+
+	"github.com/Xjs/cryptopals/modes"
+	"github.com/Xjs/cryptopals/padding"
+)
+
+func main() {
+	raw, err := ioutil.ReadFile("data.txt")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	decoded := make([]byte, base64.StdEncoding.DecodedLen(len(raw)))
+	n, err := base64.StdEncoding.Decode(decoded, raw)
+	if err != nil {
+		log.Fatal(err)
+	}
+	decoded = decoded[:n]
+	log.Println("decoded", n, "bytes")
+
+	block, err := aes.NewCipher([]byte("YELLOW SUBMARINE"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	decrypted, err := modes.DecryptCBC(block, make([]byte, aes.BlockSize), decoded)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	unpadded, err := padding.Unpad(decrypted, aes.BlockSize)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(string(unpadded))
+}
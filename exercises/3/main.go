@@ -5,11 +5,7 @@ import (
 	"fmt"
 	"log"
 
-	"github.com/Xjs/cryptopals/statistics"
-
 	"github.com/Xjs/cryptopals/crack/english"
-
-	"github.com/Xjs/cryptopals/xor"
 )
 
 func main() {
@@ -18,21 +14,10 @@ func main() {
 		log.Fatal(err)
 	}
 
-	// reference := getCharFrequency(englishText)
-
-	scores := make(map[byte]statistics.Score)
-
-	for i := 0; i < 256; i++ {
-		b := byte(i)
-		candidate := string(xor.Single(input, b))
-
-		scores[b] = english.GetScore(candidate, english.ReferenceHistogram)
-	}
-
-	fs := statistics.NewByteScoreHistogram(scores)
+	fs, plaintexts := english.FindSingleByteKeyCandidates(input, 5, nil)
 	fmt.Println(fs)
 
 	b := fs.GetHigh(0).Byte
 
-	fmt.Println(string(xor.Single(input, b)))
+	fmt.Println(string(plaintexts[b]))
 }
@@ -20,7 +20,7 @@ func main() {
 
 	for _, line := range lines {
 		input, _ := hex.DecodeString(line)
-		result, key, score, err := english.FindSingleByteKey(input)
+		result, key, score, err := english.FindSingleByteKey(input, nil)
 		if err != nil {
 			continue
 		}
@@ -2,29 +2,15 @@ package main
 
 import (
 	"encoding/base64"
-	"errors"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
 	"strconv"
 
-	"github.com/Xjs/cryptopals/crack/english"
-	"github.com/Xjs/cryptopals/sliceops"
-	"github.com/Xjs/cryptopals/statistics"
+	"github.com/Xjs/cryptopals/crack/repeatingxor"
 )
 
-func keysizeScore(input []byte, keysize int) (statistics.Score, error) {
-	if len(input) < 2*keysize {
-		return 0.0, errors.New("input too short")
-	}
-
-	first := input[:keysize]
-	second := input[keysize : 2*keysize]
-
-	return statistics.RelativeScore(sliceops.HammingDistance(first, second), keysize), nil
-}
-
 func main() {
 	raw, err := ioutil.ReadFile("data.txt")
 	if err != nil {
@@ -39,48 +25,24 @@ func main() {
 	decoded = decoded[:n]
 	log.Println("decoded", n, "bytes")
 
-	scores := make(map[int]statistics.Score)
-	for keysize := 2; keysize < 40; keysize++ {
-		samples, _ := strconv.Atoi(os.Getenv("SAMPLES"))
-		if samples == 0 {
-			samples = 1
-		}
-		var sum statistics.Score
-		for j := 0; j < samples; j++ {
-			score, err := keysizeScore(decoded[2*j*keysize:], keysize)
-			if err != nil {
-				log.Println(err)
-				continue
-			}
-
-			sum += score
+	// SAMPLES limits the key size scores to the first SAMPLES pairs of blocks;
+	// all blocks are compared if it is unset
+	var samples int
+	if s := os.Getenv("SAMPLES"); s != "" {
+		samples, err = strconv.Atoi(s)
+		if err != nil || samples < 1 {
+			log.Fatalf("SAMPLES must be a positive number of block pairs, got %q", s)
 		}
-		sum /= statistics.Score(samples)
-		scores[keysize] = sum
 	}
 
-	hist := statistics.NewSizeScoreHistogram(scores)
-	log.Println(hist)
-
-	var decrypted string
-	var lowestNonEnglish int = 1000
-	for _, tup := range hist {
-		log.Println("Trying", tup.Size, "with score", tup.Score)
-
-		result, nonEnglish, err := english.TryKeylen(decoded, tup.Size)
-		if nonEnglish < lowestNonEnglish {
-			lowestNonEnglish = nonEnglish
-		}
-		if err != nil {
-			continue
-		}
+	candidates, err := repeatingxor.BreakRepeatingKeyXOR(decoded, &repeatingxor.Options{MaxBlocks: 2 * samples})
+	if err != nil {
+		log.Fatal(err)
+	}
 
-		if nonEnglish <= lowestNonEnglish && err == nil {
-			log.Println("possible match")
-			decrypted = string(result)
-			break
-		}
+	for _, c := range candidates {
+		log.Printf("key %q (size %d, score %.2f, confidence %.2f)", c.Key, len(c.Key), c.KeySizeScore, c.Confidence)
 	}
 
-	fmt.Println(decrypted)
+	fmt.Println(string(candidates[0].Plaintext))
 }
@@ -0,0 +1,144 @@
+// Package md implements the Merkle–Damgård construction shared by MD4, MD5,
+// SHA-1 and SHA-256: buffering of input into blocks, length padding, and
+// resuming from an arbitrary state. The hashes only supply their initial
+// state, byte order and compression function.
+package md
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// BlockSize is the block size of all supported hashes in bytes
+const BlockSize = 64
+
+// MaxWords is the largest number of 32-bit state words a hash may have
+const MaxWords = 8
+
+var (
+	// ErrUnalignedLength is returned if a processed length is not a multiple of BlockSize
+	ErrUnalignedLength = errors.New("md: processed length is not a multiple of the block size")
+	// ErrInvalidDigest is returned if a digest does not match the size of the state
+	ErrInvalidDigest = errors.New("md: invalid digest length")
+)
+
+// A BlockFunc applies a compression function to state for a single block of BlockSize bytes
+type BlockFunc func(state []uint32, block []byte)
+
+// Digest is a hash.Hash built from a compression function
+type Digest struct {
+	h     [MaxWords]uint32
+	init  [MaxWords]uint32
+	words int
+	order binary.ByteOrder
+	block BlockFunc
+
+	x   [BlockSize]byte
+	nx  int
+	len uint64
+}
+
+// New returns a Digest starting from init, which also determines the size of
+// the state and digest. order is used to encode the digest and the message
+// length in the padding. New panics if init has more than MaxWords words.
+func New(init []uint32, order binary.ByteOrder, block BlockFunc) *Digest {
+	if len(init) > MaxWords {
+		panic("md: state too large")
+	}
+	d := &Digest{words: len(init), order: order, block: block}
+	copy(d.init[:], init)
+	d.Reset()
+	return d
+}
+
+// SetState makes d continue from state after length bytes have been
+// processed. length must be a multiple of BlockSize, i.e. include the padding
+// of a previous message. Buffered input is discarded.
+func (d *Digest) SetState(state []uint32, length uint64) error {
+	if length%BlockSize != 0 {
+		return ErrUnalignedLength
+	}
+	if len(state) != d.words {
+		return ErrInvalidDigest
+	}
+	copy(d.h[:], state)
+	d.nx = 0
+	d.len = length
+	return nil
+}
+
+// State returns a copy of the state words and the number of processed bytes.
+// Bytes that do not yet fill a block are not part of the state.
+func (d *Digest) State() ([]uint32, uint64) {
+	return append([]uint32(nil), d.h[:d.words]...), d.len - uint64(d.nx)
+}
+
+// StateFromDigest decodes a digest of the given number of words in byte order
+func StateFromDigest(digest []byte, order binary.ByteOrder, words int) ([]uint32, error) {
+	if len(digest) != 4*words {
+		return nil, ErrInvalidDigest
+	}
+	state := make([]uint32, words)
+	for i := range state {
+		state[i] = order.Uint32(digest[4*i:])
+	}
+	return state, nil
+}
+
+// Padding returns the padding appended to a message of messageLen bytes:
+// 0x80, zeros, and the message length in bits as a 64-bit number in the given
+// byte order, up to a multiple of BlockSize.
+func Padding(order binary.ByteOrder, messageLen uint64) []byte {
+	n := BlockSize - (messageLen+8)%BlockSize
+	padding := make([]byte, n+8)
+	padding[0] = 0x80
+	order.PutUint64(padding[n:], messageLen<<3)
+	return padding
+}
+
+// Reset implements hash.Hash
+func (d *Digest) Reset() {
+	d.h = d.init
+	d.nx = 0
+	d.len = 0
+}
+
+// Size implements hash.Hash
+func (d *Digest) Size() int { return 4 * d.words }
+
+// BlockSize implements hash.Hash
+func (d *Digest) BlockSize() int { return BlockSize }
+
+// Write implements hash.Hash. It never returns an error.
+func (d *Digest) Write(p []byte) (int, error) {
+	n := len(p)
+	d.len += uint64(n)
+	if d.nx > 0 {
+		k := copy(d.x[d.nx:], p)
+		d.nx += k
+		p = p[k:]
+		if d.nx < BlockSize {
+			return n, nil
+		}
+		d.block(d.h[:d.words], d.x[:])
+		d.nx = 0
+	}
+	for len(p) >= BlockSize {
+		d.block(d.h[:d.words], p[:BlockSize])
+		p = p[BlockSize:]
+	}
+	d.nx = copy(d.x[:], p)
+	return n, nil
+}
+
+// Sum implements hash.Hash. It does not change the state of d.
+func (d *Digest) Sum(in []byte) []byte {
+	d0 := *d
+	d0.Write(Padding(d0.order, d0.len))
+	var word [4]byte
+	for _, v := range d0.h[:d0.words] {
+		d0.order.PutUint32(word[:], v)
+		in = append(in, word[:]...)
+	}
+	return in
+}
@@ -0,0 +1,43 @@
+package md
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// sum is a toy compression function that adds up the block's words
+func sum(state []uint32, block []byte) {
+	for i := 0; i < BlockSize; i += 4 {
+		state[i/4%len(state)] += binary.LittleEndian.Uint32(block[i:])
+	}
+}
+
+func TestState(t *testing.T) {
+	d := New([]uint32{1, 2}, binary.LittleEndian, sum)
+	d.Write(make([]byte, BlockSize+3))
+	if state, length := d.State(); length != BlockSize || state[0] != 1 || state[1] != 2 {
+		t.Errorf("State() = %v, %d, want [1 2], %d", state, length, BlockSize)
+	}
+
+	tests := []struct {
+		name   string
+		state  []uint32
+		length uint64
+		want   error
+	}{
+		{"ok", []uint32{5, 6}, 2 * BlockSize, nil},
+		{"unaligned", []uint32{5, 6}, 10, ErrUnalignedLength},
+		{"words", []uint32{5, 6, 7}, BlockSize, ErrInvalidDigest},
+	}
+	for _, tt := range tests {
+		if err := d.SetState(tt.state, tt.length); err != tt.want {
+			t.Errorf("%s: SetState() error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+	if state, length := d.State(); length != 2*BlockSize || state[0] != 5 || state[1] != 6 {
+		t.Errorf("State() after SetState = %v, %d", state, length)
+	}
+	if d.Size() != 8 {
+		t.Errorf("Size() = %d, want 8", d.Size())
+	}
+}
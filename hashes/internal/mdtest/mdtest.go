@@ -0,0 +1,111 @@
+// Package mdtest checks implementations of Merkle–Damgård hashes against a
+// reference implementation, if there is one, including resuming from a digest.
+package mdtest
+
+import (
+	"bytes"
+	"hash"
+	"math/rand"
+	"testing"
+)
+
+// A Hash describes the implementation under test
+type Hash struct {
+	New func() hash.Hash
+	// Resume continues from the state that produced digest after length bytes
+	Resume  func(digest []byte, length uint64) (hash.Hash, error)
+	Padding func(messageLen uint64) []byte
+	// Reference computes the expected digest, e.g. with the standard library.
+	// Without one, digests are only checked for consistency with a single Write.
+	Reference func(data []byte) []byte
+}
+
+// sum returns the expected digest of data
+func (h Hash) sum(data []byte) []byte {
+	if h.Reference != nil {
+		return h.Reference(data)
+	}
+	d := h.New()
+	d.Write(data)
+	return d.Sum(nil)
+}
+
+// Run runs all checks for h
+func Run(t *testing.T, h Hash, seed int64) {
+	if h.Reference != nil {
+		t.Run("random", func(t *testing.T) { random(t, h, seed) })
+	}
+	t.Run("chunks", func(t *testing.T) { chunks(t, h, seed) })
+	t.Run("padding", func(t *testing.T) { padding(t, h) })
+	t.Run("resume", func(t *testing.T) { resume(t, h) })
+}
+
+func random(t *testing.T, h Hash, seed int64) {
+	r := rand.New(rand.NewSource(seed))
+	for i := 0; i < 500; i++ {
+		data := make([]byte, r.Intn(300))
+		r.Read(data)
+		d := h.New()
+		d.Write(data)
+		if got, want := d.Sum(nil), h.Reference(data); !bytes.Equal(got, want) {
+			t.Fatalf("Sum(%x) = %x, want %x", data, got, want)
+		}
+	}
+}
+
+func chunks(t *testing.T, h Hash, seed int64) {
+	r := rand.New(rand.NewSource(seed))
+	data := make([]byte, 1000)
+	r.Read(data)
+	want := h.sum(data)
+
+	d := h.New()
+	for p := data; len(p) > 0; {
+		n := r.Intn(100)
+		if n > len(p) {
+			n = len(p)
+		}
+		d.Write(p[:n])
+		p = p[n:]
+	}
+	if got := d.Sum(nil); !bytes.Equal(got, want) {
+		t.Errorf("Sum() = %x, want %x", got, want)
+	}
+	// Sum must not change the state
+	if got := d.Sum([]byte("prefix")); !bytes.Equal(got[len("prefix"):], want) {
+		t.Errorf("second Sum() = %x, want %x", got, want)
+	}
+}
+
+func padding(t *testing.T, h Hash) {
+	blockSize := uint64(h.New().BlockSize())
+	for n := uint64(0); n < 200; n++ {
+		p := h.Padding(n)
+		if (n+uint64(len(p)))%blockSize != 0 || len(p) < 9 || uint64(len(p)) > blockSize+8 {
+			t.Errorf("Padding(%d) has length %d", n, len(p))
+		}
+	}
+}
+
+func resume(t *testing.T, h Hash) {
+	message := []byte("comment1=cooking%20MCs;userdata=foo")
+	suffix := []byte(";admin=true")
+	digest := h.sum(message)
+	glued := append(append(append([]byte(nil), message...), h.Padding(uint64(len(message)))...), suffix...)
+
+	d, err := h.Resume(digest, uint64(len(glued)-len(suffix)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d.Write(suffix)
+	if got, want := d.Sum(nil), h.sum(glued); !bytes.Equal(got, want) {
+		t.Errorf("resumed Sum() = %x, want %x", got, want)
+	}
+
+	if _, err := h.Resume(digest, 10); err == nil {
+		t.Error("Resume() with an unaligned length succeeded")
+	}
+	if _, err := h.Resume(digest[:10], 64); err == nil {
+		t.Error("Resume() with a short digest succeeded")
+	}
+}
@@ -0,0 +1,168 @@
+// Package md4 implements the MD4 hash algorithm as defined in RFC 1320.
+//
+// It allows resuming a hash from an arbitrary internal state, and exports
+// its auxiliary and round functions for use in length extension and
+// collision attacks. MD4 is broken and must not be used for anything but
+// such exercises.
+package md4
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+
+	"github.com/Xjs/cryptopals/hashes/internal/md"
+)
+
+// Size is the size of an MD4 digest in bytes
+const Size = 16
+
+// BlockSize is the block size of MD4 in bytes
+const BlockSize = md.BlockSize
+
+// Init is the initial state of MD4
+var Init = [4]uint32{0x67452301, 0xefcdab89, 0x98badcfe, 0x10325476}
+
+// Additive constants of rounds 2 and 3
+const (
+	Round2Constant = 0x5a827999
+	Round3Constant = 0x6ed9eba1
+)
+
+// Shifts are the rotation amounts of each round, indexed by step modulo 4
+var Shifts = [3][4]int{{3, 7, 11, 19}, {3, 5, 9, 13}, {3, 9, 11, 15}}
+
+// Round2Order and Round3Order are the message word indices used by the steps
+// of rounds 2 and 3. Round 1 uses the words in order.
+var (
+	Round2Order = [16]int{0, 4, 8, 12, 1, 5, 9, 13, 2, 6, 10, 14, 3, 7, 11, 15}
+	Round3Order = [16]int{0, 8, 4, 12, 2, 10, 6, 14, 1, 9, 5, 13, 3, 11, 7, 15}
+)
+
+// F is the auxiliary function of round 1: if x then y else z
+func F(x, y, z uint32) uint32 { return x&y | ^x&z }
+
+// G is the auxiliary function of round 2: the majority of x, y and z
+func G(x, y, z uint32) uint32 { return x&y | x&z | y&z }
+
+// H is the auxiliary function of round 3
+func H(x, y, z uint32) uint32 { return x ^ y ^ z }
+
+// Round1 computes a step of round 1, (a + F(b, c, d) + x) <<< s
+func Round1(a, b, c, d, x uint32, s int) uint32 {
+	return bits.RotateLeft32(a+F(b, c, d)+x, s)
+}
+
+// Round2 computes a step of round 2, (a + G(b, c, d) + x + Round2Constant) <<< s
+func Round2(a, b, c, d, x uint32, s int) uint32 {
+	return bits.RotateLeft32(a+G(b, c, d)+x+Round2Constant, s)
+}
+
+// Round3 computes a step of round 3, (a + H(b, c, d) + x + Round3Constant) <<< s
+func Round3(a, b, c, d, x uint32, s int) uint32 {
+	return bits.RotateLeft32(a+H(b, c, d)+x+Round3Constant, s)
+}
+
+// Words decodes a block of BlockSize bytes into its 16 little-endian message words
+func Words(p []byte) [16]uint32 {
+	var x [16]uint32
+	for i := range x {
+		x[i] = binary.LittleEndian.Uint32(p[4*i:])
+	}
+	return x
+}
+
+// Block applies the compression function to state for a single block of
+// message words
+func Block(state *[4]uint32, x *[16]uint32) {
+	a, b, c, d := state[0], state[1], state[2], state[3]
+	for i := 0; i < 16; i++ {
+		a, b, c, d = d, Round1(a, b, c, d, x[i], Shifts[0][i%4]), b, c
+	}
+	for i := 0; i < 16; i++ {
+		a, b, c, d = d, Round2(a, b, c, d, x[Round2Order[i]], Shifts[1][i%4]), b, c
+	}
+	for i := 0; i < 16; i++ {
+		a, b, c, d = d, Round3(a, b, c, d, x[Round3Order[i]], Shifts[2][i%4]), b, c
+	}
+
+	state[0] += a
+	state[1] += b
+	state[2] += c
+	state[3] += d
+}
+
+// Errors when resuming from a state, as documented in hashes/internal/md
+var (
+	ErrUnalignedLength = md.ErrUnalignedLength
+	ErrInvalidDigest   = md.ErrInvalidDigest
+)
+
+// Digest is an MD4 hash.Hash whose state can be inspected
+type Digest struct {
+	*md.Digest
+}
+
+// New returns a new Digest computing the MD4 checksum
+func New() *Digest {
+	return &Digest{md.New(Init[:], binary.LittleEndian, block)}
+}
+
+// NewFromState returns a Digest that continues from state after length bytes
+func NewFromState(state [4]uint32, length uint64) (*Digest, error) {
+	d := New()
+	if err := d.SetState(state[:], length); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// NewFromDigest returns a Digest that continues from the state that produced digest
+func NewFromDigest(digest []byte, length uint64) (*Digest, error) {
+	state, err := StateFromDigest(digest)
+	if err != nil {
+		return nil, err
+	}
+	return NewFromState(state, length)
+}
+
+// StateFromDigest decodes digest into registers
+func StateFromDigest(digest []byte) ([4]uint32, error) {
+	var state [4]uint32
+	words, err := md.StateFromDigest(digest, binary.LittleEndian, len(state))
+	copy(state[:], words)
+	return state, err
+}
+
+// MDPadding returns the padding MD4 appends to a message of messageLen bytes
+func MDPadding(messageLen uint64) []byte {
+	return md.Padding(binary.LittleEndian, messageLen)
+}
+
+// Sum returns the MD4 checksum of data
+func Sum(data []byte) [Size]byte {
+	d := New()
+	d.Write(data)
+	var result [Size]byte
+	d.Sum(result[:0])
+	return result
+}
+
+// State returns the registers and the number of processed bytes
+func (d *Digest) State() ([4]uint32, uint64) {
+	var state [4]uint32
+	words, length := d.Digest.State()
+	copy(state[:], words)
+	return state, length
+}
+
+// block adapts Block to md.BlockFunc
+func block(state []uint32, p []byte) {
+	var s [4]uint32
+	copy(s[:], state)
+	x := Words(p)
+	Block(&s, &x)
+	copy(state, s[:])
+}
+
+var _ hash.Hash = (*Digest)(nil)
@@ -0,0 +1,63 @@
+package md4
+
+import (
+	"encoding/hex"
+	"hash"
+	"testing"
+
+	"github.com/Xjs/cryptopals/hashes/internal/mdtest"
+)
+
+// TestSum checks the test suite of RFC 1320, appendix A.5, and the examples
+// of the Wikipedia article on MD4, which span more than one block
+func TestSum(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"", "31d6cfe0d16ae931b73c59d7e0c089c0"},
+		{"a", "bde52cb31de33e46245e05fbdbd6fb24"},
+		{"abc", "a448017aaf21d8525fc10ae87aa6729d"},
+		{"message digest", "d9130a8164549fe818874806e1c7014b"},
+		{"abcdefghijklmnopqrstuvwxyz", "d79e1c308aa5bbcdeea8ed63df412da9"},
+		{"ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789", "043f8582f241db351ce627e153e7f0e4"},
+		{"12345678901234567890123456789012345678901234567890123456789012345678901234567890", "e33b4ddc9c38f2199c3e7b164fcc0536"},
+		{"The quick brown fox jumps over the lazy dog", "1bee69a46ba811185c194762abaeae90"},
+		{"The quick brown fox jumps over the lazy cog", "b86e130ce7028da59e672d56ad0113df"},
+	}
+	for _, tt := range tests {
+		got := Sum([]byte(tt.input))
+		if hex.EncodeToString(got[:]) != tt.want {
+			t.Errorf("Sum(%q) = %x, want %s", tt.input, got, tt.want)
+		}
+	}
+}
+
+// TestDigest checks chunked writes and resuming. There is no reference
+// implementation to compare random inputs with; TestSum has known answers.
+func TestDigest(t *testing.T) {
+	mdtest.Run(t, mdtest.Hash{
+		New: func() hash.Hash { return New() },
+		Resume: func(digest []byte, length uint64) (hash.Hash, error) {
+			return NewFromDigest(digest, length)
+		},
+		Padding: MDPadding,
+	}, 30)
+}
+
+func TestBlock(t *testing.T) {
+	// A single padded block through Block must match Sum
+	padded := append([]byte("abc"), MDPadding(3)...)
+	state := Init
+	x := Words(padded)
+	Block(&state, &x)
+
+	want := Sum([]byte("abc"))
+	got, err := StateFromDigest(want[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state != got {
+		t.Errorf("Block() = %x, want %x", state, got)
+	}
+}
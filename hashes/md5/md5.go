@@ -0,0 +1,134 @@
+// Package md5 implements the MD5 hash algorithm as defined in RFC 1321.
+//
+// Unlike crypto/md5, it allows resuming a hash from an arbitrary internal
+// state, which is what length extension attacks need. MD5 is broken and
+// must not be used for anything but such exercises.
+package md5
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+
+	"github.com/Xjs/cryptopals/hashes/internal/md"
+)
+
+// Size is the size of an MD5 digest in bytes
+const Size = 16
+
+// BlockSize is the block size of MD5 in bytes
+const BlockSize = md.BlockSize
+
+// Init is the initial state of MD5
+var Init = [4]uint32{0x67452301, 0xefcdab89, 0x98badcfe, 0x10325476}
+
+// shifts are the per-round rotation amounts
+var shifts = [4][4]int{{7, 12, 17, 22}, {5, 9, 14, 20}, {4, 11, 16, 23}, {6, 10, 15, 21}}
+
+// t is the sine table of RFC 1321
+var t = [64]uint32{
+	0xd76aa478, 0xe8c7b756, 0x242070db, 0xc1bdceee, 0xf57c0faf, 0x4787c62a, 0xa8304613, 0xfd469501,
+	0x698098d8, 0x8b44f7af, 0xffff5bb1, 0x895cd7be, 0x6b901122, 0xfd987193, 0xa679438e, 0x49b40821,
+	0xf61e2562, 0xc040b340, 0x265e5a51, 0xe9b6c7aa, 0xd62f105d, 0x02441453, 0xd8a1e681, 0xe7d3fbc8,
+	0x21e1cde6, 0xc33707d6, 0xf4d50d87, 0x455a14ed, 0xa9e3e905, 0xfcefa3f8, 0x676f02d9, 0x8d2a4c8a,
+	0xfffa3942, 0x8771f681, 0x6d9d6122, 0xfde5380c, 0xa4beea44, 0x4bdecfa9, 0xf6bb4b60, 0xbebfbc70,
+	0x289b7ec6, 0xeaa127fa, 0xd4ef3085, 0x04881d05, 0xd9d4d039, 0xe6db99e5, 0x1fa27cf8, 0xc4ac5665,
+	0xf4292244, 0x432aff97, 0xab9423a7, 0xfc93a039, 0x655b59c3, 0x8f0ccc92, 0xffeff47d, 0x85845dd1,
+	0x6fa87e4f, 0xfe2ce6e0, 0xa3014314, 0x4e0811a1, 0xf7537e82, 0xbd3af235, 0x2ad7d2bb, 0xeb86d391,
+}
+
+// Errors when resuming from a state, as documented in hashes/internal/md
+var (
+	ErrUnalignedLength = md.ErrUnalignedLength
+	ErrInvalidDigest   = md.ErrInvalidDigest
+)
+
+// Digest is an MD5 hash.Hash whose state can be inspected
+type Digest struct {
+	*md.Digest
+}
+
+// New returns a new Digest computing the MD5 checksum
+func New() *Digest {
+	return &Digest{md.New(Init[:], binary.LittleEndian, block)}
+}
+
+// NewFromState returns a Digest that continues from state after length bytes
+func NewFromState(state [4]uint32, length uint64) (*Digest, error) {
+	d := New()
+	if err := d.SetState(state[:], length); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// NewFromDigest returns a Digest that continues from the state that produced digest
+func NewFromDigest(digest []byte, length uint64) (*Digest, error) {
+	state, err := StateFromDigest(digest)
+	if err != nil {
+		return nil, err
+	}
+	return NewFromState(state, length)
+}
+
+// StateFromDigest decodes digest into registers
+func StateFromDigest(digest []byte) ([4]uint32, error) {
+	var state [4]uint32
+	words, err := md.StateFromDigest(digest, binary.LittleEndian, len(state))
+	copy(state[:], words)
+	return state, err
+}
+
+// MDPadding returns the padding MD5 appends to a message of messageLen bytes
+func MDPadding(messageLen uint64) []byte {
+	return md.Padding(binary.LittleEndian, messageLen)
+}
+
+// Sum returns the MD5 checksum of data
+func Sum(data []byte) [Size]byte {
+	d := New()
+	d.Write(data)
+	var result [Size]byte
+	d.Sum(result[:0])
+	return result
+}
+
+// State returns the registers and the number of processed bytes
+func (d *Digest) State() ([4]uint32, uint64) {
+	var state [4]uint32
+	words, length := d.Digest.State()
+	copy(state[:], words)
+	return state, length
+}
+
+// block processes a single block of BlockSize bytes
+func block(s []uint32, p []byte) {
+	var x [16]uint32
+	for i := range x {
+		x[i] = binary.LittleEndian.Uint32(p[4*i:])
+	}
+
+	a, b, c, d := s[0], s[1], s[2], s[3]
+	for i := 0; i < 64; i++ {
+		var f uint32
+		var g int
+		switch i / 16 {
+		case 0:
+			f, g = b&c|^b&d, i
+		case 1:
+			f, g = b&d|c&^d, (5*i+1)%16
+		case 2:
+			f, g = b^c^d, (3*i+5)%16
+		default:
+			f, g = c^(b|^d), (7*i)%16
+		}
+		a, b, c, d = d, b+bits.RotateLeft32(a+f+t[i]+x[g], shifts[i/16][i%4]), b, c
+	}
+
+	s[0] += a
+	s[1] += b
+	s[2] += c
+	s[3] += d
+}
+
+var _ hash.Hash = (*Digest)(nil)
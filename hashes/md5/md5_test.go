@@ -0,0 +1,23 @@
+package md5
+
+import (
+	stdmd5 "crypto/md5"
+	"hash"
+	"testing"
+
+	"github.com/Xjs/cryptopals/hashes/internal/mdtest"
+)
+
+func TestDigest(t *testing.T) {
+	mdtest.Run(t, mdtest.Hash{
+		New: func() hash.Hash { return New() },
+		Resume: func(digest []byte, length uint64) (hash.Hash, error) {
+			return NewFromDigest(digest, length)
+		},
+		Padding: MDPadding,
+		Reference: func(data []byte) []byte {
+			sum := stdmd5.Sum(data)
+			return sum[:]
+		},
+	}, 5)
+}
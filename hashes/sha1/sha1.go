@@ -0,0 +1,123 @@
+// Package sha1 implements the SHA-1 hash algorithm as defined in RFC 3174.
+//
+// Unlike crypto/sha1, it allows resuming a hash from an arbitrary internal
+// state, which is what length extension attacks need. SHA-1 is broken and
+// must not be used for anything but such exercises.
+package sha1
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+
+	"github.com/Xjs/cryptopals/hashes/internal/md"
+)
+
+// Size is the size of a SHA-1 digest in bytes
+const Size = 20
+
+// BlockSize is the block size of SHA-1 in bytes
+const BlockSize = md.BlockSize
+
+// Init is the initial state of SHA-1
+var Init = [5]uint32{0x67452301, 0xefcdab89, 0x98badcfe, 0x10325476, 0xc3d2e1f0}
+
+// Errors when resuming from a state, as documented in hashes/internal/md
+var (
+	ErrUnalignedLength = md.ErrUnalignedLength
+	ErrInvalidDigest   = md.ErrInvalidDigest
+)
+
+// Digest is a SHA-1 hash.Hash whose state can be inspected
+type Digest struct {
+	*md.Digest
+}
+
+// New returns a new Digest computing the SHA-1 checksum
+func New() *Digest {
+	return &Digest{md.New(Init[:], binary.BigEndian, block)}
+}
+
+// NewFromState returns a Digest that continues from state after length bytes
+func NewFromState(state [5]uint32, length uint64) (*Digest, error) {
+	d := New()
+	if err := d.SetState(state[:], length); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// NewFromDigest returns a Digest that continues from the state that produced digest
+func NewFromDigest(digest []byte, length uint64) (*Digest, error) {
+	state, err := StateFromDigest(digest)
+	if err != nil {
+		return nil, err
+	}
+	return NewFromState(state, length)
+}
+
+// StateFromDigest decodes digest into registers
+func StateFromDigest(digest []byte) ([5]uint32, error) {
+	var state [5]uint32
+	words, err := md.StateFromDigest(digest, binary.BigEndian, len(state))
+	copy(state[:], words)
+	return state, err
+}
+
+// MDPadding returns the padding SHA-1 appends to a message of messageLen bytes
+func MDPadding(messageLen uint64) []byte {
+	return md.Padding(binary.BigEndian, messageLen)
+}
+
+// Sum returns the SHA-1 checksum of data
+func Sum(data []byte) [Size]byte {
+	d := New()
+	d.Write(data)
+	var result [Size]byte
+	d.Sum(result[:0])
+	return result
+}
+
+// State returns the registers and the number of processed bytes
+func (d *Digest) State() ([5]uint32, uint64) {
+	var state [5]uint32
+	words, length := d.Digest.State()
+	copy(state[:], words)
+	return state, length
+}
+
+// block processes a single block of BlockSize bytes
+func block(h []uint32, p []byte) {
+	var w [80]uint32
+	for i := 0; i < 16; i++ {
+		w[i] = binary.BigEndian.Uint32(p[4*i:])
+	}
+	for i := 16; i < 80; i++ {
+		w[i] = bits.RotateLeft32(w[i-3]^w[i-8]^w[i-14]^w[i-16], 1)
+	}
+
+	a, b, c, d, e := h[0], h[1], h[2], h[3], h[4]
+	for i := 0; i < 80; i++ {
+		var f, k uint32
+		switch {
+		case i < 20:
+			f, k = b&c|^b&d, 0x5a827999
+		case i < 40:
+			f, k = b^c^d, 0x6ed9eba1
+		case i < 60:
+			f, k = b&c|b&d|c&d, 0x8f1bbcdc
+		default:
+			f, k = b^c^d, 0xca62c1d6
+		}
+		t := bits.RotateLeft32(a, 5) + f + e + k + w[i]
+		a, b, c, d, e = t, a, bits.RotateLeft32(b, 30), c, d
+	}
+
+	h[0] += a
+	h[1] += b
+	h[2] += c
+	h[3] += d
+	h[4] += e
+}
+
+var _ hash.Hash = (*Digest)(nil)
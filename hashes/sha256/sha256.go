@@ -0,0 +1,132 @@
+// Package sha256 implements the SHA-256 hash algorithm as defined in FIPS 180-4.
+//
+// Unlike crypto/sha256, it allows resuming a hash from an arbitrary internal
+// state, which is what length extension attacks need.
+package sha256
+
+import (
+	"encoding/binary"
+	"hash"
+	"math/bits"
+
+	"github.com/Xjs/cryptopals/hashes/internal/md"
+)
+
+// Size is the size of a SHA-256 digest in bytes
+const Size = 32
+
+// BlockSize is the block size of SHA-256 in bytes
+const BlockSize = md.BlockSize
+
+// Init is the initial state of SHA-256
+var Init = [8]uint32{0x6a09e667, 0xbb67ae85, 0x3c6ef372, 0xa54ff53a, 0x510e527f, 0x9b05688c, 0x1f83d9ab, 0x5be0cd19}
+
+var k = [64]uint32{
+	0x428a2f98, 0x71374491, 0xb5c0fbcf, 0xe9b5dba5, 0x3956c25b, 0x59f111f1, 0x923f82a4, 0xab1c5ed5,
+	0xd807aa98, 0x12835b01, 0x243185be, 0x550c7dc3, 0x72be5d74, 0x80deb1fe, 0x9bdc06a7, 0xc19bf174,
+	0xe49b69c1, 0xefbe4786, 0x0fc19dc6, 0x240ca1cc, 0x2de92c6f, 0x4a7484aa, 0x5cb0a9dc, 0x76f988da,
+	0x983e5152, 0xa831c66d, 0xb00327c8, 0xbf597fc7, 0xc6e00bf3, 0xd5a79147, 0x06ca6351, 0x14292967,
+	0x27b70a85, 0x2e1b2138, 0x4d2c6dfc, 0x53380d13, 0x650a7354, 0x766a0abb, 0x81c2c92e, 0x92722c85,
+	0xa2bfe8a1, 0xa81a664b, 0xc24b8b70, 0xc76c51a3, 0xd192e819, 0xd6990624, 0xf40e3585, 0x106aa070,
+	0x19a4c116, 0x1e376c08, 0x2748774c, 0x34b0bcb5, 0x391c0cb3, 0x4ed8aa4a, 0x5b9cca4f, 0x682e6ff3,
+	0x748f82ee, 0x78a5636f, 0x84c87814, 0x8cc70208, 0x90befffa, 0xa4506ceb, 0xbef9a3f7, 0xc67178f2,
+}
+
+// Errors when resuming from a state, as documented in hashes/internal/md
+var (
+	ErrUnalignedLength = md.ErrUnalignedLength
+	ErrInvalidDigest   = md.ErrInvalidDigest
+)
+
+// Digest is a SHA-256 hash.Hash whose state can be inspected
+type Digest struct {
+	*md.Digest
+}
+
+// New returns a new Digest computing the SHA-256 checksum
+func New() *Digest {
+	return &Digest{md.New(Init[:], binary.BigEndian, block)}
+}
+
+// NewFromState returns a Digest that continues from state after length bytes
+func NewFromState(state [8]uint32, length uint64) (*Digest, error) {
+	d := New()
+	if err := d.SetState(state[:], length); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// NewFromDigest returns a Digest that continues from the state that produced digest
+func NewFromDigest(digest []byte, length uint64) (*Digest, error) {
+	state, err := StateFromDigest(digest)
+	if err != nil {
+		return nil, err
+	}
+	return NewFromState(state, length)
+}
+
+// StateFromDigest decodes digest into registers
+func StateFromDigest(digest []byte) ([8]uint32, error) {
+	var state [8]uint32
+	words, err := md.StateFromDigest(digest, binary.BigEndian, len(state))
+	copy(state[:], words)
+	return state, err
+}
+
+// MDPadding returns the padding SHA-256 appends to a message of messageLen bytes
+func MDPadding(messageLen uint64) []byte {
+	return md.Padding(binary.BigEndian, messageLen)
+}
+
+// Sum returns the SHA-256 checksum of data
+func Sum(data []byte) [Size]byte {
+	d := New()
+	d.Write(data)
+	var result [Size]byte
+	d.Sum(result[:0])
+	return result
+}
+
+// State returns the registers and the number of processed bytes
+func (d *Digest) State() ([8]uint32, uint64) {
+	var state [8]uint32
+	words, length := d.Digest.State()
+	copy(state[:], words)
+	return state, length
+}
+
+// block processes a single block of BlockSize bytes
+func block(h []uint32, p []byte) {
+	var w [64]uint32
+	for i := 0; i < 16; i++ {
+		w[i] = binary.BigEndian.Uint32(p[4*i:])
+	}
+	for i := 16; i < 64; i++ {
+		s0 := bits.RotateLeft32(w[i-15], -7) ^ bits.RotateLeft32(w[i-15], -18) ^ w[i-15]>>3
+		s1 := bits.RotateLeft32(w[i-2], -17) ^ bits.RotateLeft32(w[i-2], -19) ^ w[i-2]>>10
+		w[i] = w[i-16] + s0 + w[i-7] + s1
+	}
+
+	a, b, c, d, e, f, g, hh := h[0], h[1], h[2], h[3], h[4], h[5], h[6], h[7]
+	for i := 0; i < 64; i++ {
+		s1 := bits.RotateLeft32(e, -6) ^ bits.RotateLeft32(e, -11) ^ bits.RotateLeft32(e, -25)
+		ch := e&f ^ ^e&g
+		t1 := hh + s1 + ch + k[i] + w[i]
+		s0 := bits.RotateLeft32(a, -2) ^ bits.RotateLeft32(a, -13) ^ bits.RotateLeft32(a, -22)
+		maj := a&b ^ a&c ^ b&c
+		t2 := s0 + maj
+		a, b, c, d, e, f, g, hh = t1+t2, a, b, c, d+t1, e, f, g
+	}
+
+	h[0] += a
+	h[1] += b
+	h[2] += c
+	h[3] += d
+	h[4] += e
+	h[5] += f
+	h[6] += g
+	h[7] += hh
+}
+
+var _ hash.Hash = (*Digest)(nil)
@@ -0,0 +1,23 @@
+package sha256
+
+import (
+	stdsha256 "crypto/sha256"
+	"hash"
+	"testing"
+
+	"github.com/Xjs/cryptopals/hashes/internal/mdtest"
+)
+
+func TestDigest(t *testing.T) {
+	mdtest.Run(t, mdtest.Hash{
+		New: func() hash.Hash { return New() },
+		Resume: func(digest []byte, length uint64) (hash.Hash, error) {
+			return NewFromDigest(digest, length)
+		},
+		Padding: MDPadding,
+		Reference: func(data []byte) []byte {
+			sum := stdsha256.Sum256(data)
+			return sum[:]
+		},
+	}, 256)
+}
@@ -0,0 +1,150 @@
+// Package kvcookie encodes and parses k=v&k=v cookies, like the user profiles
+// of challenge 13. Metacharacters in keys and values are percent-escaped.
+package kvcookie
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrEmptyKey is returned for pairs without a key
+	ErrEmptyKey = errors.New("kvcookie: empty key")
+	// ErrMissingValue is returned for pairs without '='
+	ErrMissingValue = errors.New("kvcookie: missing '='")
+	// ErrDuplicateKey is returned if a key occurs more than once
+	ErrDuplicateKey = errors.New("kvcookie: duplicate key")
+	// ErrBadEscape is returned for malformed or unnecessary percent escapes
+	// and for unescaped metacharacters
+	ErrBadEscape = errors.New("kvcookie: invalid escape")
+)
+
+// A SyntaxError describes which pair of a cookie could not be parsed
+type SyntaxError struct {
+	// Pair is the 0-based index of the offending pair
+	Pair int
+	Err  error
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("kvcookie: pair %d: %v", e.Pair, e.Err)
+}
+
+// A Pair is a single key and value
+type Pair struct {
+	Key, Value string
+}
+
+// Values is an ordered list of pairs with distinct keys
+type Values []Pair
+
+// Get returns the value for key, or "" if there is none
+func (v Values) Get(key string) string {
+	for _, p := range v {
+		if p.Key == key {
+			return p.Value
+		}
+	}
+	return ""
+}
+
+// Encode returns the k=v&k=v representation of v, escaping metacharacters
+func (v Values) Encode() string {
+	parts := make([]string, len(v))
+	for i, p := range v {
+		parts[i] = Escape(p.Key) + "=" + Escape(p.Value)
+	}
+	return strings.Join(parts, "&")
+}
+
+func (v Values) String() string { return v.Encode() }
+
+const hexDigits = "0123456789ABCDEF"
+
+// isMeta returns whether c must be escaped
+func isMeta(c byte) bool {
+	return c == '&' || c == '=' || c == '%'
+}
+
+// Escape percent-escapes the metacharacters '&', '=' and '%' in s
+func Escape(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if isMeta(c) {
+			b.WriteByte('%')
+			b.WriteByte(hexDigits[c>>4])
+			b.WriteByte(hexDigits[c&0xf])
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
+
+// Unescape reverses Escape. It is strict: only metacharacters may be escaped,
+// escapes must use upper-case hex digits and metacharacters must not occur unescaped.
+func Unescape(s string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c != '%' {
+			if isMeta(c) {
+				return "", ErrBadEscape
+			}
+			b.WriteByte(c)
+			continue
+		}
+		if i+2 >= len(s) {
+			return "", ErrBadEscape
+		}
+		hi, lo := strings.IndexByte(hexDigits, s[i+1]), strings.IndexByte(hexDigits, s[i+2])
+		if hi < 0 || lo < 0 || !isMeta(byte(hi<<4|lo)) {
+			return "", ErrBadEscape
+		}
+		b.WriteByte(byte(hi<<4 | lo))
+		i += 2
+	}
+	return b.String(), nil
+}
+
+// Parse parses a k=v&k=v cookie. It rejects empty keys, pairs without '=',
+// duplicate keys and invalid escapes with a *SyntaxError.
+func Parse(s string) (Values, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	var result Values
+	seen := make(map[string]bool)
+	for i, part := range strings.Split(s, "&") {
+		eq := strings.IndexByte(part, '=')
+		if eq < 0 {
+			return nil, &SyntaxError{Pair: i, Err: ErrMissingValue}
+		}
+		key, err := Unescape(part[:eq])
+		if err != nil {
+			return nil, &SyntaxError{Pair: i, Err: err}
+		}
+		value, err := Unescape(part[eq+1:])
+		if err != nil {
+			return nil, &SyntaxError{Pair: i, Err: err}
+		}
+		if key == "" {
+			return nil, &SyntaxError{Pair: i, Err: ErrEmptyKey}
+		}
+		if seen[key] {
+			return nil, &SyntaxError{Pair: i, Err: ErrDuplicateKey}
+		}
+		seen[key] = true
+		result = append(result, Pair{Key: key, Value: value})
+	}
+	return result, nil
+}
+
+// ProfileFor returns the profile of challenge 13 for the given email address:
+// email=...&uid=10&role=user
+func ProfileFor(email string) Values {
+	return Values{{"email", email}, {"uid", "10"}, {"role", "user"}}
+}
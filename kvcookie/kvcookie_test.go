@@ -0,0 +1,61 @@
+package kvcookie
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    Values
+		wantErr error
+	}{
+		{"challenge", "foo=bar&baz=qux&zap=zazzle", Values{{"foo", "bar"}, {"baz", "qux"}, {"zap", "zazzle"}}, nil},
+		{"empty-value", "role=", Values{{"role", ""}}, nil},
+		{"escaped", "email=foo%40bar.com%26role%3Dadmin", nil, ErrBadEscape},
+		{"escaped-meta", "email=foo@bar.com%26role%3Dadmin", Values{{"email", "foo@bar.com&role=admin"}}, nil},
+		{"empty", "", nil, nil},
+		{"missing-equals", "foo=bar&baz", nil, ErrMissingValue},
+		{"empty-key", "=bar", nil, ErrEmptyKey},
+		{"duplicate", "role=user&role=admin", nil, ErrDuplicateKey},
+		{"raw-equals", "a=b=c", nil, ErrBadEscape},
+		{"truncated-escape", "a=%2", nil, ErrBadEscape},
+		{"lowercase-escape", "a=%3d", nil, ErrBadEscape},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if tt.wantErr != nil {
+				se, ok := err.(*SyntaxError)
+				if !ok || se.Err != tt.wantErr {
+					t.Errorf("Parse() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProfileFor(t *testing.T) {
+	if got, want := ProfileFor("foo@bar.com").Encode(), "email=foo@bar.com&uid=10&role=user"; got != want {
+		t.Errorf("ProfileFor() = %q, want %q", got, want)
+	}
+
+	// Injected metacharacters must not create new pairs
+	encoded := ProfileFor("foo@bar.com&role=admin").Encode()
+	parsed, err := Parse(encoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Get("role") != "user" || parsed.Get("email") != "foo@bar.com&role=admin" {
+		t.Errorf("round trip of %q gave %v", encoded, parsed)
+	}
+}
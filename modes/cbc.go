@@ -0,0 +1,140 @@
+package modes
+
+import (
+	"crypto/cipher"
+	"errors"
+
+	"github.com/Xjs/cryptopals/sliceops"
+)
+
+// ErrInvalidIV is returned when the IV length does not match the block size
+var ErrInvalidIV = errors.New("modes: IV length must equal block size")
+
+// A CBCStep describes how a single block was processed in CBC mode.
+// The slices may be kept, but must not be modified.
+type CBCStep struct {
+	// Index is the number of the block, counted across calls to CryptBlocks
+	Index int
+	// Previous is the IV for the first block, or the preceding ciphertext block
+	Previous []byte
+	// Intermediate is the input to the block cipher when encrypting
+	// (Plaintext XOR Previous), or its output when decrypting.
+	Intermediate []byte
+	Plaintext    []byte
+	Ciphertext   []byte
+}
+
+// A CBCHook is called after each block processed by a CBC
+type CBCHook func(step CBCStep)
+
+// CBC is a cipher.BlockMode implementing cipher block chaining by hand, composed
+// of single-block ECB and XOR. It exposes every intermediate state via Hook,
+// which makes it suitable for studying bit-flipping and padding oracle attacks.
+type CBC struct {
+	ecb     cipher.BlockMode
+	iv      []byte
+	encrypt bool
+	index   int
+	// Hook, if not nil, is called for every processed block
+	Hook CBCHook
+}
+
+func newCBC(b cipher.Block, iv []byte, encrypt bool) (*CBC, error) {
+	if len(iv) != b.BlockSize() {
+		return nil, ErrInvalidIV
+	}
+
+	c := &CBC{iv: make([]byte, len(iv)), encrypt: encrypt}
+	copy(c.iv, iv)
+	if encrypt {
+		c.ecb = NewECBEncrypter(b)
+	} else {
+		c.ecb = NewECBDecrypter(b)
+	}
+	return c, nil
+}
+
+// NewCBCEncrypter returns a CBC which encrypts with the given block cipher and IV.
+// The IV is updated across calls to CryptBlocks, like with cipher.NewCBCEncrypter.
+func NewCBCEncrypter(b cipher.Block, iv []byte) (*CBC, error) {
+	return newCBC(b, iv, true)
+}
+
+// NewCBCDecrypter returns a CBC which decrypts with the given block cipher and IV
+func NewCBCDecrypter(b cipher.Block, iv []byte) (*CBC, error) {
+	return newCBC(b, iv, false)
+}
+
+// BlockSize returns the block size of the underlying block cipher
+func (c *CBC) BlockSize() int { return c.ecb.BlockSize() }
+
+// CryptBlocks encrypts or decrypts src into dst. It panics if src is not a
+// multiple of the block size or dst is too small; use CryptBlocks to get an error instead.
+func (c *CBC) CryptBlocks(dst, src []byte) {
+	if err := check(c, dst, src); err != nil {
+		panic(err)
+	}
+
+	bs := c.BlockSize()
+	for i := 0; i < len(src); i += bs {
+		in := make([]byte, bs)
+		copy(in, src[i:i+bs])
+
+		step := CBCStep{Index: c.index, Previous: c.iv, Intermediate: make([]byte, bs)}
+		var out []byte
+		var err error
+		if c.encrypt {
+			step.Plaintext = in
+			step.Intermediate, err = sliceops.MapOperator(in, c.iv, sliceops.XOR)
+			if err != nil {
+				panic(err)
+			}
+			out = make([]byte, bs)
+			c.ecb.CryptBlocks(out, step.Intermediate)
+			step.Ciphertext = out
+			c.iv = out
+		} else {
+			step.Ciphertext = in
+			c.ecb.CryptBlocks(step.Intermediate, in)
+			out, err = sliceops.MapOperator(step.Intermediate, c.iv, sliceops.XOR)
+			if err != nil {
+				panic(err)
+			}
+			step.Plaintext = out
+			c.iv = in
+		}
+
+		copy(dst[i:i+bs], out)
+		c.index++
+
+		if c.Hook != nil {
+			c.Hook(step)
+		}
+	}
+}
+
+// EncryptCBC encrypts plaintext, which must consist of full blocks, in CBC mode
+func EncryptCBC(b cipher.Block, iv, plaintext []byte) ([]byte, error) {
+	c, err := NewCBCEncrypter(b, iv)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]byte, len(plaintext))
+	if err := CryptBlocks(c, result, plaintext); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DecryptCBC decrypts ciphertext, which must consist of full blocks, in CBC mode
+func DecryptCBC(b cipher.Block, iv, ciphertext []byte) ([]byte, error) {
+	c, err := NewCBCDecrypter(b, iv)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]byte, len(ciphertext))
+	if err := CryptBlocks(c, result, ciphertext); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
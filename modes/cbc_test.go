@@ -0,0 +1,110 @@
+package modes
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"testing"
+)
+
+func TestCBCMatchesStdlib(t *testing.T) {
+	key := make([]byte, 16)
+	iv := make([]byte, 16)
+	plaintext := make([]byte, 16*20)
+	rand.Read(key)
+	rand.Read(iv)
+	rand.Read(plaintext)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := make([]byte, len(plaintext))
+	cipher.NewCBCEncrypter(block, iv).CryptBlocks(want, plaintext)
+
+	// Encrypt in two calls to check that the IV is carried over
+	enc, err := NewCBCEncrypter(block, iv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := make([]byte, len(plaintext))
+	enc.CryptBlocks(got[:48], plaintext[:48])
+	enc.CryptBlocks(got[48:], plaintext[48:])
+	if !bytes.Equal(got, want) {
+		t.Error("CBC encryption differs from crypto/cipher")
+	}
+
+	decrypted, err := DecryptCBC(block, iv, want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(decrypted, plaintext) {
+		t.Error("DecryptCBC() did not restore the plaintext")
+	}
+}
+
+func TestCBCHook(t *testing.T) {
+	block, err := aes.NewCipher([]byte("YELLOW SUBMARINE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	iv := []byte("0123456789abcdef")
+	plaintext := []byte("first block 0001second block 002third block 0003")
+
+	var encSteps, decSteps []CBCStep
+	enc, err := NewCBCEncrypter(block, iv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc.Hook = func(s CBCStep) { encSteps = append(encSteps, s) }
+	ciphertext := make([]byte, len(plaintext))
+	enc.CryptBlocks(ciphertext, plaintext)
+
+	dec, err := NewCBCDecrypter(block, iv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dec.Hook = func(s CBCStep) { decSteps = append(decSteps, s) }
+	decrypted := make([]byte, len(ciphertext))
+	dec.CryptBlocks(decrypted, ciphertext)
+
+	if len(encSteps) != 3 || len(decSteps) != 3 {
+		t.Fatalf("got %d encryption and %d decryption steps, want 3", len(encSteps), len(decSteps))
+	}
+	for i := range encSteps {
+		e, d := encSteps[i], decSteps[i]
+		if e.Index != i || d.Index != i {
+			t.Errorf("step %d: indices %d, %d", i, e.Index, d.Index)
+		}
+		if !bytes.Equal(e.Intermediate, d.Intermediate) {
+			t.Errorf("step %d: intermediate states differ", i)
+		}
+		if !bytes.Equal(e.Plaintext, plaintext[i*16:(i+1)*16]) || !bytes.Equal(d.Plaintext, e.Plaintext) {
+			t.Errorf("step %d: plaintext %q, %q", i, e.Plaintext, d.Plaintext)
+		}
+		if !bytes.Equal(e.Ciphertext, ciphertext[i*16:(i+1)*16]) || !bytes.Equal(d.Ciphertext, e.Ciphertext) {
+			t.Errorf("step %d: ciphertext mismatch", i)
+		}
+		if !bytes.Equal(e.Previous, d.Previous) {
+			t.Errorf("step %d: previous blocks differ", i)
+		}
+	}
+	if !bytes.Equal(encSteps[0].Previous, iv) {
+		t.Error("first step does not chain from the IV")
+	}
+}
+
+func TestCBCErrors(t *testing.T) {
+	block, err := aes.NewCipher([]byte("YELLOW SUBMARINE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewCBCEncrypter(block, make([]byte, 8)); err != ErrInvalidIV {
+		t.Errorf("NewCBCEncrypter() error = %v, want %v", err, ErrInvalidIV)
+	}
+	if _, err := EncryptCBC(block, make([]byte, 16), make([]byte, 20)); err != ErrNotFullBlocks {
+		t.Errorf("EncryptCBC() error = %v, want %v", err, ErrNotFullBlocks)
+	}
+}
@@ -0,0 +1,126 @@
+package modes
+
+import (
+	"crypto/cipher"
+	"errors"
+	"io"
+)
+
+// ErrInvalidLayout is returned if a CTRLayout does not fit the block size
+var ErrInvalidLayout = errors.New("modes: nonce and counter sizes must add up to block size")
+
+// ErrNegativeOffset is returned by XORKeyStreamAt and Seek for negative offsets
+var ErrNegativeOffset = errors.New("modes: negative offset")
+
+// ErrInvalidWhence is returned by Seek for unsupported whence values
+var ErrInvalidWhence = errors.New("modes: invalid whence")
+
+// A CTRLayout describes how a counter block is made up: NonceSize bytes of
+// nonce, followed by CounterSize bytes of counter in the given byte order.
+// The counter wraps around within its bytes and never carries into the nonce.
+type CTRLayout struct {
+	NonceSize, CounterSize int
+	LittleEndian           bool
+}
+
+var (
+	// Challenge18Layout is a 64-bit nonce followed by a 64-bit little-endian counter
+	Challenge18Layout = CTRLayout{NonceSize: 8, CounterSize: 8, LittleEndian: true}
+	// StdlibLayout treats the whole 128-bit block as a big-endian counter, like cipher.NewCTR
+	StdlibLayout = CTRLayout{NonceSize: 0, CounterSize: 16}
+)
+
+// CTR is a cipher.Stream in counter mode with a configurable counter layout.
+// Besides sequential use through XORKeyStream, it allows random access to the
+// key stream through XORKeyStreamAt.
+type CTR struct {
+	b      cipher.Block
+	iv     []byte
+	layout CTRLayout
+	offset int64
+}
+
+// NewCTR creates a CTR for the block cipher b. iv is the first counter block,
+// i.e. the nonce followed by the initial counter value, laid out as described by layout.
+func NewCTR(b cipher.Block, iv []byte, layout CTRLayout) (*CTR, error) {
+	if layout.NonceSize < 0 || layout.CounterSize <= 0 || layout.NonceSize+layout.CounterSize != b.BlockSize() {
+		return nil, ErrInvalidLayout
+	}
+	if len(iv) != b.BlockSize() {
+		return nil, ErrInvalidIV
+	}
+
+	c := &CTR{b: b, iv: make([]byte, len(iv)), layout: layout}
+	copy(c.iv, iv)
+	return c, nil
+}
+
+// counterBlock returns the counter block for the block with the given index
+func (c *CTR) counterBlock(index uint64) []byte {
+	block := make([]byte, len(c.iv))
+	copy(block, c.iv)
+
+	counter := block[c.layout.NonceSize:]
+	carry := index
+	for i := 0; i < len(counter) && carry != 0; i++ {
+		pos := len(counter) - 1 - i
+		if c.layout.LittleEndian {
+			pos = i
+		}
+		sum := uint64(counter[pos]) + carry&0xff
+		counter[pos] = byte(sum)
+		carry = carry>>8 + sum>>8
+	}
+	return block
+}
+
+// XORKeyStreamAt XORs src with the key stream starting at the given byte offset
+// and writes the result to dst. It does not change the position used by
+// XORKeyStream. It panics if dst is shorter than src.
+func (c *CTR) XORKeyStreamAt(dst, src []byte, offset int64) error {
+	if offset < 0 {
+		return ErrNegativeOffset
+	}
+	if len(dst) < len(src) {
+		panic(ErrOutputTooSmall)
+	}
+
+	bs := int64(c.b.BlockSize())
+	keystream := make([]byte, bs)
+	for i := 0; i < len(src); {
+		pos := offset + int64(i)
+		c.b.Encrypt(keystream, c.counterBlock(uint64(pos/bs)))
+		for k := pos % bs; k < bs && i < len(src); k++ {
+			dst[i] = src[i] ^ keystream[k]
+			i++
+		}
+	}
+	return nil
+}
+
+// XORKeyStream XORs src with the key stream at the current position, writes
+// the result to dst and advances the position by len(src).
+// It panics if dst is shorter than src.
+func (c *CTR) XORKeyStream(dst, src []byte) {
+	if err := c.XORKeyStreamAt(dst, src, c.offset); err != nil {
+		panic(err)
+	}
+	c.offset += int64(len(src))
+}
+
+// Seek sets the position used by the next call to XORKeyStream, like io.Seeker.
+// Since the key stream has no end, io.SeekEnd is not supported.
+func (c *CTR) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += c.offset
+	default:
+		return c.offset, ErrInvalidWhence
+	}
+	if offset < 0 {
+		return c.offset, ErrNegativeOffset
+	}
+	c.offset = offset
+	return offset, nil
+}
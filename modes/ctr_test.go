@@ -0,0 +1,141 @@
+package modes
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"testing"
+)
+
+// TestCTRChallenge18 is challenge 18
+func TestCTRChallenge18(t *testing.T) {
+	ciphertext, err := base64.StdEncoding.DecodeString("L77na/nrFsKvynd6HzOoG7GHTLXsTVu9qvY/2syLXzhPweyyMTJULu/6/kXX0KSvoOLSFQ==")
+	if err != nil {
+		t.Fatal(err)
+	}
+	block, err := aes.NewCipher([]byte("YELLOW SUBMARINE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctr, err := NewCTR(block, make([]byte, aes.BlockSize), Challenge18Layout)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := make([]byte, len(ciphertext))
+	ctr.XORKeyStream(got, ciphertext)
+	if want := "Yo, VIP Let's kick it Ice, Ice, baby Ice, Ice, baby "; string(got) != want {
+		t.Errorf("XORKeyStream() = %q, want %q", got, want)
+	}
+}
+
+func TestCTRMatchesStdlib(t *testing.T) {
+	key := make([]byte, 16)
+	rand.Read(key)
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext := make([]byte, 1000)
+	rand.Read(plaintext)
+
+	for _, iv := range [][]byte{
+		make([]byte, 16),
+		bytes.Repeat([]byte{0xff}, 16), // wraps around the full block
+		append(bytes.Repeat([]byte{0x42}, 8), 0, 0, 0, 0, 0, 0, 0xff, 0xf0),
+	} {
+		want := make([]byte, len(plaintext))
+		cipher.NewCTR(block, iv).XORKeyStream(want, plaintext)
+
+		ctr, err := NewCTR(block, iv, StdlibLayout)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got := make([]byte, len(plaintext))
+		ctr.XORKeyStream(got[:7], plaintext[:7])
+		ctr.XORKeyStream(got[7:500], plaintext[7:500])
+		ctr.XORKeyStream(got[500:], plaintext[500:])
+		if !bytes.Equal(got, want) {
+			t.Errorf("iv %x: CTR differs from crypto/cipher", iv)
+		}
+	}
+}
+
+func TestCTRCounterWrapsWithinCounter(t *testing.T) {
+	block, err := aes.NewCipher([]byte("YELLOW SUBMARINE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	iv := []byte("noncenon\xff\xff\xff\xff\xff\xff\xff\xff")
+	ctr, err := NewCTR(block, iv, Challenge18Layout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ctr.counterBlock(1); !bytes.Equal(got, []byte("noncenon\x00\x00\x00\x00\x00\x00\x00\x00")) {
+		t.Errorf("counterBlock(1) = %x", got)
+	}
+
+	iv = []byte("noncenon\xff\x00\x00\x00\x00\x00\x00\xff")
+	ctr, err = NewCTR(block, iv, Challenge18Layout)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ctr.counterBlock(1); !bytes.Equal(got, []byte("noncenon\x00\x01\x00\x00\x00\x00\x00\xff")) {
+		t.Errorf("counterBlock(1) = %x", got)
+	}
+	ctr.layout.LittleEndian = false
+	if got := ctr.counterBlock(1); !bytes.Equal(got, []byte("noncenon\xff\x00\x00\x00\x00\x00\x01\x00")) {
+		t.Errorf("big-endian counterBlock(1) = %x", got)
+	}
+}
+
+func TestCTRXORKeyStreamAt(t *testing.T) {
+	block, err := aes.NewCipher([]byte("YELLOW SUBMARINE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctr, err := NewCTR(block, make([]byte, 16), Challenge18Layout)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	plaintext := bytes.Repeat([]byte("random access "), 20)
+	full := make([]byte, len(plaintext))
+	if err := ctr.XORKeyStreamAt(full, plaintext, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, offset := range []int{0, 1, 15, 16, 17, 100, len(plaintext) - 1} {
+		part := make([]byte, len(plaintext)-offset)
+		if err := ctr.XORKeyStreamAt(part, plaintext[offset:], int64(offset)); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(part, full[offset:]) {
+			t.Errorf("offset %d: XORKeyStreamAt differs", offset)
+		}
+
+		if _, err := ctr.Seek(int64(offset), io.SeekStart); err != nil {
+			t.Fatal(err)
+		}
+		ctr.XORKeyStream(part, plaintext[offset:])
+		if !bytes.Equal(part, full[offset:]) {
+			t.Errorf("offset %d: XORKeyStream after Seek differs", offset)
+		}
+	}
+
+	if pos, err := ctr.Seek(-1, io.SeekCurrent); err != nil || pos != int64(len(plaintext)-1) {
+		t.Errorf("Seek(-1, io.SeekCurrent) = %d, %v", pos, err)
+	}
+	if _, err := ctr.Seek(0, io.SeekEnd); err != ErrInvalidWhence {
+		t.Errorf("Seek() error = %v, want %v", err, ErrInvalidWhence)
+	}
+	if err := ctr.XORKeyStreamAt(nil, nil, -1); err != ErrNegativeOffset {
+		t.Errorf("XORKeyStreamAt() error = %v, want %v", err, ErrNegativeOffset)
+	}
+	if _, err := NewCTR(block, make([]byte, 16), CTRLayout{NonceSize: 8, CounterSize: 4}); err != ErrInvalidLayout {
+		t.Errorf("NewCTR() error = %v, want %v", err, ErrInvalidLayout)
+	}
+}
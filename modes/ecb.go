@@ -0,0 +1,91 @@
+// Package modes implements block cipher modes of operation that are missing
+// from crypto/cipher, like ECB.
+package modes
+
+import (
+	"crypto/cipher"
+	"errors"
+)
+
+// ErrNotFullBlocks is returned when the input is not a multiple of the block size
+var ErrNotFullBlocks = errors.New("modes: input not full blocks")
+
+// ErrOutputTooSmall is returned when the output is shorter than the input
+var ErrOutputTooSmall = errors.New("modes: output smaller than input")
+
+type ecb struct {
+	b       cipher.Block
+	encrypt bool
+}
+
+// NewECBEncrypter returns a cipher.BlockMode which encrypts in electronic
+// codebook mode, using the given cipher.Block. Each block is encrypted
+// independently, so equal plaintext blocks give equal ciphertext blocks.
+func NewECBEncrypter(b cipher.Block) cipher.BlockMode {
+	return &ecb{b: b, encrypt: true}
+}
+
+// NewECBDecrypter returns a cipher.BlockMode which decrypts in electronic
+// codebook mode, using the given cipher.Block.
+func NewECBDecrypter(b cipher.Block) cipher.BlockMode {
+	return &ecb{b: b}
+}
+
+func (e *ecb) BlockSize() int { return e.b.BlockSize() }
+
+// CryptBlocks encrypts or decrypts src block by block into dst. Like all
+// cipher.BlockMode implementations, it panics if src is not a multiple of
+// the block size or dst is too small; use CryptBlocks to get an error instead.
+func (e *ecb) CryptBlocks(dst, src []byte) {
+	if err := check(e, dst, src); err != nil {
+		panic(err)
+	}
+
+	bs := e.b.BlockSize()
+	for i := 0; i < len(src); i += bs {
+		if e.encrypt {
+			e.b.Encrypt(dst[i:i+bs], src[i:i+bs])
+		} else {
+			e.b.Decrypt(dst[i:i+bs], src[i:i+bs])
+		}
+	}
+}
+
+func check(mode cipher.BlockMode, dst, src []byte) error {
+	if len(src)%mode.BlockSize() != 0 {
+		return ErrNotFullBlocks
+	}
+	if len(dst) < len(src) {
+		return ErrOutputTooSmall
+	}
+	return nil
+}
+
+// CryptBlocks calls mode.CryptBlocks(dst, src) after checking that src consists
+// of full blocks and dst is large enough, returning an error instead of panicking.
+// It works with any cipher.BlockMode.
+func CryptBlocks(mode cipher.BlockMode, dst, src []byte) error {
+	if err := check(mode, dst, src); err != nil {
+		return err
+	}
+	mode.CryptBlocks(dst, src)
+	return nil
+}
+
+// EncryptECB encrypts plaintext, which must consist of full blocks, in ECB mode
+func EncryptECB(b cipher.Block, plaintext []byte) ([]byte, error) {
+	result := make([]byte, len(plaintext))
+	if err := CryptBlocks(NewECBEncrypter(b), result, plaintext); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DecryptECB decrypts ciphertext, which must consist of full blocks, in ECB mode
+func DecryptECB(b cipher.Block, ciphertext []byte) ([]byte, error) {
+	result := make([]byte, len(ciphertext))
+	if err := CryptBlocks(NewECBDecrypter(b), result, ciphertext); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
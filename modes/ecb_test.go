@@ -0,0 +1,76 @@
+package modes
+
+import (
+	"bytes"
+	"crypto/aes"
+	"encoding/hex"
+	"testing"
+)
+
+func mustHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func TestECB(t *testing.T) {
+	// NIST SP 800-38A, F.1.1 ECB-AES128
+	block, err := aes.NewCipher(mustHex(t, "2b7e151628aed2a6abf7158809cf4f3c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext := mustHex(t, "6bc1bee22e409f96e93d7e117393172aae2d8a571e03ac9c9eb76fac45af8e51")
+	ciphertext := mustHex(t, "3ad77bb40d7a3660a89ecaf32466ef97f5d3d58503b9699de785895a96fdbaaf")
+
+	got, err := EncryptECB(block, plaintext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, ciphertext) {
+		t.Errorf("EncryptECB() = %x, want %x", got, ciphertext)
+	}
+
+	got, err = DecryptECB(block, ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, plaintext) {
+		t.Errorf("DecryptECB() = %x, want %x", got, plaintext)
+	}
+}
+
+func TestCryptBlocksErrors(t *testing.T) {
+	block, err := aes.NewCipher([]byte("YELLOW SUBMARINE"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mode := NewECBEncrypter(block)
+
+	tests := []struct {
+		name    string
+		dst     []byte
+		src     []byte
+		wantErr error
+	}{
+		{"aligned", make([]byte, 32), make([]byte, 32), nil},
+		{"empty", nil, nil, nil},
+		{"unaligned", make([]byte, 32), make([]byte, 17), ErrNotFullBlocks},
+		{"short-output", make([]byte, 16), make([]byte, 32), ErrOutputTooSmall},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CryptBlocks(mode, tt.dst, tt.src); err != tt.wantErr {
+				t.Errorf("CryptBlocks() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("CryptBlocks method did not panic on unaligned input")
+		}
+	}()
+	mode.CryptBlocks(make([]byte, 17), make([]byte, 17))
+}
@@ -0,0 +1,149 @@
+// Package mt implements the Mersenne Twister pseudo random number generators
+// MT19937 and MT19937-64 as described by Matsumoto and Nishimura.
+//
+// Both implement math/rand.Source64, so they can be used with rand.New.
+// They are not suitable for cryptographic purposes.
+package mt
+
+// Parameters of MT19937
+const (
+	N = 624
+	M = 397
+
+	MatrixA   uint32 = 0x9908b0df
+	UpperMask uint32 = 0x80000000
+	LowerMask uint32 = 0x7fffffff
+
+	// Tempering parameters
+	U        = 11
+	S        = 7
+	B uint32 = 0x9d2c5680
+	T        = 15
+	C uint32 = 0xefc60000
+	L        = 18
+
+	// DefaultSeed is the seed used by the reference implementation if none is given
+	DefaultSeed = 5489
+)
+
+// MT19937 is the 32-bit Mersenne Twister
+type MT19937 struct {
+	state [N]uint32
+	index int
+}
+
+// New creates an MT19937 seeded with seed
+func New(seed uint32) *MT19937 {
+	m := &MT19937{}
+	m.SeedUint32(seed)
+	return m
+}
+
+// NewByArray creates an MT19937 seeded with key, like init_by_array
+func NewByArray(key []uint32) *MT19937 {
+	m := &MT19937{}
+	m.SeedByArray(key)
+	return m
+}
+
+// SeedUint32 initialises the state from seed, like init_genrand
+func (m *MT19937) SeedUint32(seed uint32) {
+	m.state[0] = seed
+	for i := 1; i < N; i++ {
+		m.state[i] = 1812433253*(m.state[i-1]^(m.state[i-1]>>30)) + uint32(i)
+	}
+	m.index = N
+}
+
+// SeedByArray initialises the state from key, like init_by_array
+// An empty key is treated like a key of a single zero, which is what Python's
+// random module does for seed 0.
+func (m *MT19937) SeedByArray(key []uint32) {
+	if len(key) == 0 {
+		key = []uint32{0}
+	}
+	m.SeedUint32(19650218)
+	i, j := 1, 0
+	k := N
+	if len(key) > k {
+		k = len(key)
+	}
+	for ; k > 0; k-- {
+		m.state[i] = (m.state[i] ^ ((m.state[i-1] ^ (m.state[i-1] >> 30)) * 1664525)) + key[j] + uint32(j)
+		i++
+		j++
+		if i >= N {
+			m.state[0] = m.state[N-1]
+			i = 1
+		}
+		if j >= len(key) {
+			j = 0
+		}
+	}
+	for k = N - 1; k > 0; k-- {
+		m.state[i] = (m.state[i] ^ ((m.state[i-1] ^ (m.state[i-1] >> 30)) * 1566083941)) - uint32(i)
+		i++
+		if i >= N {
+			m.state[0] = m.state[N-1]
+			i = 1
+		}
+	}
+	m.state[0] = 0x80000000
+	m.index = N
+}
+
+// NewFromState creates an MT19937 from a raw state, e.g. one recovered by
+// untempering outputs. index is the position within state of the word that is
+// tempered for the next output; with index N, the state is twisted first.
+func NewFromState(state [N]uint32, index int) *MT19937 {
+	return &MT19937{state: state, index: index}
+}
+
+// Seed implements rand.Source by seeding with the lower 32 bits of seed
+func (m *MT19937) Seed(seed int64) {
+	m.SeedUint32(uint32(seed))
+}
+
+// twist generates the next N words of state
+func (m *MT19937) twist() {
+	for i := 0; i < N; i++ {
+		y := (m.state[i] & UpperMask) | (m.state[(i+1)%N] & LowerMask)
+		next := m.state[(i+M)%N] ^ (y >> 1)
+		if y&1 != 0 {
+			next ^= MatrixA
+		}
+		m.state[i] = next
+	}
+	m.index = 0
+}
+
+// Temper applies the MT19937 tempering transform to a word of state
+func Temper(y uint32) uint32 {
+	y ^= y >> U
+	y ^= (y << S) & B
+	y ^= (y << T) & C
+	y ^= y >> L
+	return y
+}
+
+// Uint32 returns the next output, like genrand_int32
+func (m *MT19937) Uint32() uint32 {
+	if m.index >= N {
+		m.twist()
+	}
+	y := m.state[m.index]
+	m.index++
+	return Temper(y)
+}
+
+// Uint64 implements rand.Source64 by combining two outputs, the first one
+// giving the upper 32 bits
+func (m *MT19937) Uint64() uint64 {
+	hi := uint64(m.Uint32())
+	return hi<<32 | uint64(m.Uint32())
+}
+
+// Int63 implements rand.Source
+func (m *MT19937) Int63() int64 {
+	return int64(m.Uint64() >> 1)
+}
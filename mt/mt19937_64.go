@@ -0,0 +1,115 @@
+package mt
+
+// Parameters of MT19937-64
+const (
+	N64 = 312
+	M64 = 156
+
+	MatrixA64   uint64 = 0xb5026f5aa96619e9
+	UpperMask64 uint64 = 0xffffffff80000000
+	LowerMask64 uint64 = 0x7fffffff
+)
+
+// MT19937x64 is the 64-bit Mersenne Twister MT19937-64
+type MT19937x64 struct {
+	state [N64]uint64
+	index int
+}
+
+// New64 creates an MT19937x64 seeded with seed
+func New64(seed uint64) *MT19937x64 {
+	m := &MT19937x64{}
+	m.SeedUint64(seed)
+	return m
+}
+
+// NewByArray64 creates an MT19937x64 seeded with key, like init_by_array64
+func NewByArray64(key []uint64) *MT19937x64 {
+	m := &MT19937x64{}
+	m.SeedByArray(key)
+	return m
+}
+
+// SeedUint64 initialises the state from seed, like init_genrand64
+func (m *MT19937x64) SeedUint64(seed uint64) {
+	m.state[0] = seed
+	for i := 1; i < N64; i++ {
+		m.state[i] = 6364136223846793005*(m.state[i-1]^(m.state[i-1]>>62)) + uint64(i)
+	}
+	m.index = N64
+}
+
+// SeedByArray initialises the state from key, like init_by_array64
+// An empty key is treated like a key of a single zero, which is what Python's
+// random module does for seed 0.
+func (m *MT19937x64) SeedByArray(key []uint64) {
+	if len(key) == 0 {
+		key = []uint64{0}
+	}
+	m.SeedUint64(19650218)
+	i, j := 1, 0
+	k := N64
+	if len(key) > k {
+		k = len(key)
+	}
+	for ; k > 0; k-- {
+		m.state[i] = (m.state[i] ^ ((m.state[i-1] ^ (m.state[i-1] >> 62)) * 3935559000370003845)) + key[j] + uint64(j)
+		i++
+		j++
+		if i >= N64 {
+			m.state[0] = m.state[N64-1]
+			i = 1
+		}
+		if j >= len(key) {
+			j = 0
+		}
+	}
+	for k = N64 - 1; k > 0; k-- {
+		m.state[i] = (m.state[i] ^ ((m.state[i-1] ^ (m.state[i-1] >> 62)) * 2862933555777941757)) - uint64(i)
+		i++
+		if i >= N64 {
+			m.state[0] = m.state[N64-1]
+			i = 1
+		}
+	}
+	m.state[0] = 1 << 63
+	m.index = N64
+}
+
+// Seed implements rand.Source
+func (m *MT19937x64) Seed(seed int64) {
+	m.SeedUint64(uint64(seed))
+}
+
+// twist generates the next N64 words of state
+func (m *MT19937x64) twist() {
+	for i := 0; i < N64; i++ {
+		x := (m.state[i] & UpperMask64) | (m.state[(i+1)%N64] & LowerMask64)
+		next := m.state[(i+M64)%N64] ^ (x >> 1)
+		if x&1 != 0 {
+			next ^= MatrixA64
+		}
+		m.state[i] = next
+	}
+	m.index = 0
+}
+
+// Uint64 returns the next output, like genrand64_int64
+func (m *MT19937x64) Uint64() uint64 {
+	if m.index >= N64 {
+		m.twist()
+	}
+	x := m.state[m.index]
+	m.index++
+
+	x ^= (x >> 29) & 0x5555555555555555
+	x ^= (x << 17) & 0x71d67fffeda60000
+	x ^= (x << 37) & 0xfff7eee000000000
+	x ^= x >> 43
+	return x
+}
+
+// Int63 implements rand.Source
+func (m *MT19937x64) Int63() int64 {
+	return int64(m.Uint64() >> 1)
+}
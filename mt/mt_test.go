@@ -0,0 +1,121 @@
+package mt
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Interface checks
+var (
+	_ rand.Source64 = &MT19937{}
+	_ rand.Source64 = &MT19937x64{}
+)
+
+func TestMT19937(t *testing.T) {
+	tests := []struct {
+		name string
+		mt   *MT19937
+		want []uint32
+	}{
+		// mt19937ar.out of the reference implementation
+		{"init-by-array", NewByArray([]uint32{0x123, 0x234, 0x345, 0x456}), []uint32{
+			1067595299, 955945823, 477289528, 4107218783, 4228976476,
+			3344332714, 3355579695, 227628506, 810200273, 2591290167,
+		}},
+		{"default-seed", New(DefaultSeed), []uint32{3499211612, 581869302, 3890346734, 3586334585, 545404204}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, want := range tt.want {
+				if got := tt.mt.Uint32(); got != want {
+					t.Errorf("output %d = %d, want %d", i, got, want)
+				}
+			}
+		})
+	}
+
+	// The C++ standard requires the 10000th output of std::mt19937 to be 4123659995
+	m := New(DefaultSeed)
+	for i := 1; i < 10000; i++ {
+		m.Uint32()
+	}
+	if got := m.Uint32(); got != 4123659995 {
+		t.Errorf("10000th output = %d, want 4123659995", got)
+	}
+}
+
+func TestMT19937x64(t *testing.T) {
+	tests := []struct {
+		name string
+		mt   *MT19937x64
+		want []uint64
+	}{
+		// mt19937-64.out of the reference implementation
+		{"init-by-array", NewByArray64([]uint64{0x12345, 0x23456, 0x34567, 0x45678}), []uint64{
+			7266447313870364031, 4946485549665804864, 16945909448695747420,
+			16394063075524226720, 4873882236456199058,
+		}},
+		{"default-seed", New64(DefaultSeed), []uint64{14514284786278117030}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, want := range tt.want {
+				if got := tt.mt.Uint64(); got != want {
+					t.Errorf("output %d = %d, want %d", i, got, want)
+				}
+			}
+		})
+	}
+
+	// The C++ standard requires the 10000th output of std::mt19937_64 to be 9981545732273789042
+	m := New64(DefaultSeed)
+	for i := 1; i < 10000; i++ {
+		m.Uint64()
+	}
+	if got := m.Uint64(); got != 9981545732273789042 {
+		t.Errorf("10000th output = %d, want 9981545732273789042", got)
+	}
+}
+
+func TestSource(t *testing.T) {
+	a := rand.New(New(42))
+	b := New(42)
+	for i := 0; i < 100; i++ {
+		if got, want := a.Int63(), int64(b.Uint64()>>1); got != want {
+			t.Fatalf("rand.Int63() = %d, want %d", got, want)
+		}
+	}
+
+	m := New(1)
+	m.Seed(42)
+	if got, want := m.Uint32(), New(42).Uint32(); got != want {
+		t.Errorf("after Seed(42): %d, want %d", got, want)
+	}
+}
+
+func TestStream(t *testing.T) {
+	plaintext := []byte("YELLOW SUBMARINE and then some")
+	ciphertext := make([]byte, len(plaintext))
+	NewStream(1234).XORKeyStream(ciphertext, plaintext)
+	if string(ciphertext) == string(plaintext) {
+		t.Fatal("XORKeyStream did not change input")
+	}
+	decrypted := make([]byte, len(ciphertext))
+	NewStream(1234).XORKeyStream(decrypted, ciphertext)
+	if string(decrypted) != string(plaintext) {
+		t.Errorf("decrypted %q, want %q", decrypted, plaintext)
+	}
+}
+
+func TestSeedByEmptyArray(t *testing.T) {
+	a, b := NewByArray(nil), NewByArray([]uint32{0})
+	a64, b64 := NewByArray64([]uint64{}), NewByArray64([]uint64{0})
+	for i := 0; i < 1000; i++ {
+		if x, y := a.Uint32(), b.Uint32(); x != y {
+			t.Fatalf("output %d: NewByArray(nil) gives %d, NewByArray([0]) gives %d", i, x, y)
+		}
+		if x, y := a64.Uint64(), b64.Uint64(); x != y {
+			t.Fatalf("output %d: NewByArray64([]) gives %d, NewByArray64([0]) gives %d", i, x, y)
+		}
+	}
+}
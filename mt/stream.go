@@ -0,0 +1,24 @@
+package mt
+
+// Stream is the MT19937 stream cipher of challenge 24. It implements
+// cipher.Stream, with a key stream made of the low byte of each output of an
+// MT19937 seeded with the key. It is, of course, insecure.
+type Stream struct {
+	mt *MT19937
+}
+
+// NewStream creates a Stream with the given seed as key. Challenge 24 uses 16-bit seeds.
+func NewStream(seed uint32) *Stream {
+	return &Stream{mt: New(seed)}
+}
+
+// XORKeyStream XORs each byte in src with the next key stream byte and writes
+// the result to dst. It panics if dst is shorter than src.
+func (s *Stream) XORKeyStream(dst, src []byte) {
+	if len(dst) < len(src) {
+		panic("mt: output smaller than input")
+	}
+	for i, b := range src {
+		dst[i] = b ^ byte(s.mt.Uint32())
+	}
+}
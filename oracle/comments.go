@@ -0,0 +1,113 @@
+package oracle
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/Xjs/cryptopals/modes"
+	"github.com/Xjs/cryptopals/padding"
+)
+
+// ErrUnsupportedMode is returned if an oracle cannot work in the requested mode
+var ErrUnsupportedMode = errors.New("oracle: unsupported mode")
+
+// The fixed parts of the comment string of Comments
+const (
+	CommentsPrefix = "comment1=cooking%20MCs;userdata="
+	CommentsSuffix = ";comment2=%20like%20a%20pound%20of%20bacon"
+)
+
+// commentsQuoter quotes the characters that would let user data add fields
+var commentsQuoter = strings.NewReplacer(";", "%3B", "=", "%3D")
+
+// Comments is the oracle of challenges 16 and 26. It quotes ';' and '=' in the
+// plaintext, embeds it between CommentsPrefix and CommentsSuffix, and encrypts
+// the result with AES-128 under a fixed random key. In CBC mode, the plaintext
+// is padded with PKCS#7 and the ciphertext is prefixed with the fixed random
+// IV. In CTR mode, a fixed random nonce is used.
+type Comments struct {
+	mode  Mode
+	block cipher.Block
+	iv    []byte
+	ctr   *modes.CTR
+}
+
+// NewComments creates a Comments oracle for mode CBC or CTR, with key and IV
+// or nonce read from random
+func NewComments(random io.Reader, mode Mode) (*Comments, error) {
+	if mode != CBC && mode != CTR {
+		return nil, ErrUnsupportedMode
+	}
+	key, err := randomBytes(random, aes.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	o := &Comments{mode: mode, block: block}
+	switch mode {
+	case CBC:
+		o.iv, err = randomBytes(random, aes.BlockSize)
+	case CTR:
+		var nonce []byte
+		nonce, err = randomBytes(random, modes.Challenge18Layout.NonceSize)
+		if err == nil {
+			iv := append(nonce, make([]byte, modes.Challenge18Layout.CounterSize)...)
+			o.ctr, err = modes.NewCTR(block, iv, modes.Challenge18Layout)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return o, nil
+}
+
+// Encrypt encrypts the comment string with plaintext as quoted user data
+func (o *Comments) Encrypt(plaintext []byte) ([]byte, error) {
+	comment := []byte(CommentsPrefix + commentsQuoter.Replace(string(plaintext)) + CommentsSuffix)
+
+	if o.mode == CTR {
+		ciphertext := make([]byte, len(comment))
+		err := o.ctr.XORKeyStreamAt(ciphertext, comment, 0)
+		return ciphertext, err
+	}
+
+	padded, err := padding.Pad(comment, aes.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext, err := modes.EncryptCBC(o.block, o.iv, padded)
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte(nil), o.iv...), ciphertext...), nil
+}
+
+// IsAdmin decrypts a ciphertext as returned by Encrypt and reports whether it
+// contains ";admin=true;"
+func (o *Comments) IsAdmin(ciphertext []byte) (bool, error) {
+	var plaintext []byte
+	if o.mode == CTR {
+		plaintext = make([]byte, len(ciphertext))
+		if err := o.ctr.XORKeyStreamAt(plaintext, ciphertext, 0); err != nil {
+			return false, err
+		}
+	} else {
+		if len(ciphertext) < aes.BlockSize {
+			return false, modes.ErrInvalidIV
+		}
+		var err error
+		plaintext, err = modes.DecryptCBC(o.block, ciphertext[:aes.BlockSize], ciphertext[aes.BlockSize:])
+		if err != nil {
+			return false, err
+		}
+	}
+	return bytes.Contains(plaintext, []byte(";admin=true;")), nil
+}
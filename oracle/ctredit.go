@@ -0,0 +1,70 @@
+package oracle
+
+import (
+	"crypto/aes"
+	"errors"
+	"io"
+
+	"github.com/Xjs/cryptopals/modes"
+)
+
+// ErrEditRange is returned if an edit starts outside the ciphertext
+var ErrEditRange = errors.New("oracle: edit out of range")
+
+// An Editor re-encrypts part of a ciphertext under a secret key
+type Editor interface {
+	// Edit returns ciphertext with the plaintext from byte offset on replaced
+	// by newtext. The result is longer than ciphertext if newtext extends past its end.
+	Edit(ciphertext []byte, offset int, newtext []byte) ([]byte, error)
+}
+
+// CTREdit is the oracle of challenge 25. It encrypts plaintext in AES-128-CTR
+// mode under a fixed random key and nonce, and exposes random-access editing
+// of ciphertexts, as a seekable disk encryption might.
+type CTREdit struct {
+	ctr *modes.CTR
+}
+
+// NewCTREdit creates a CTREdit oracle with key and nonce read from random
+func NewCTREdit(random io.Reader) (*CTREdit, error) {
+	key, err := randomBytes(random, aes.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomBytes(random, modes.Challenge18Layout.NonceSize)
+	if err != nil {
+		return nil, err
+	}
+	ctr, err := modes.NewCTR(block, append(nonce, make([]byte, modes.Challenge18Layout.CounterSize)...), modes.Challenge18Layout)
+	if err != nil {
+		return nil, err
+	}
+	return &CTREdit{ctr: ctr}, nil
+}
+
+// Encrypt encrypts plaintext from the start of the key stream
+func (o *CTREdit) Encrypt(plaintext []byte) ([]byte, error) {
+	return o.Edit(nil, 0, plaintext)
+}
+
+// Edit implements Editor
+func (o *CTREdit) Edit(ciphertext []byte, offset int, newtext []byte) ([]byte, error) {
+	if offset < 0 || offset > len(ciphertext) {
+		return nil, ErrEditRange
+	}
+	length := len(ciphertext)
+	if offset+len(newtext) > length {
+		length = offset + len(newtext)
+	}
+
+	result := make([]byte, length)
+	copy(result, ciphertext)
+	if err := o.ctr.XORKeyStreamAt(result[offset:], newtext, int64(offset)); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,55 @@
+package oracle
+
+import (
+	"bytes"
+
+	"github.com/Xjs/cryptopals/analysis"
+)
+
+// A Detection is the result of DetectMode
+type Detection struct {
+	Mode Mode
+	// Confidence is between 0 and 1
+	Confidence float64
+	// Report is the block analysis of the oracle's answer
+	Report *analysis.Report
+}
+
+// probeBlocks is the number of identical plaintext blocks DetectMode sends.
+// One of them may be spoiled by an unknown prefix.
+const probeBlocks = 4
+
+// DetectMode sends probeBlocks+1 blocks of identical bytes through o and
+// analyses the ciphertext for repeated blocks. In ECB mode, at least probeBlocks
+// ciphertext blocks are equal whatever prefix the oracle adds; in chaining modes
+// none are. The confidence is the share of the expected repetitions that were
+// (for ECB) or were not (for other modes) observed. Modes without repetitions
+// are reported as CBC.
+func DetectMode(o Oracle, blockSize int) (*Detection, error) {
+	ciphertext, err := o.Encrypt(bytes.Repeat([]byte{'A'}, (probeBlocks+1)*blockSize))
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := analysis.AnalyzeBlocks(ciphertext, blockSize)
+	if err != nil {
+		return nil, err
+	}
+
+	repeats := 1
+	if len(report.Histogram) > 0 {
+		repeats = report.Histogram.GetHigh(0).Count
+	}
+	share := float64(repeats-1) / float64(probeBlocks-1)
+	if share > 1 {
+		share = 1
+	}
+
+	d := &Detection{Report: report}
+	if report.LikelyECB {
+		d.Mode, d.Confidence = ECB, share
+	} else {
+		d.Mode, d.Confidence = CBC, 1-share
+	}
+	return d, nil
+}
@@ -0,0 +1,66 @@
+package oracle
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"fmt"
+	"io"
+
+	"github.com/Xjs/cryptopals/modes"
+	"github.com/Xjs/cryptopals/padding"
+)
+
+// A HighASCIIError is returned by IVKey.Decrypt if the plaintext is not
+// ASCII. Like a careless receiver's error message, it carries the plaintext.
+type HighASCIIError struct {
+	Plaintext []byte
+}
+
+func (e *HighASCIIError) Error() string {
+	return fmt.Sprintf("oracle: invalid ASCII in plaintext %q", e.Plaintext)
+}
+
+// IVKey is the oracle of challenge 27. It encrypts plaintext with PKCS#7
+// padding in AES-128-CBC mode, using its random key as IV as well.
+type IVKey struct {
+	block cipher.Block
+	key   []byte
+}
+
+// NewIVKey creates an IVKey oracle with a key read from random
+func NewIVKey(random io.Reader) (*IVKey, error) {
+	key, err := randomBytes(random, aes.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return &IVKey{block: block, key: key}, nil
+}
+
+// Encrypt encrypts plaintext with the key as IV
+func (o *IVKey) Encrypt(plaintext []byte) ([]byte, error) {
+	padded, err := padding.Pad(plaintext, aes.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+	return modes.EncryptCBC(o.block, o.key, padded)
+}
+
+// Decrypt is the flawed receiver. It decrypts ciphertext and checks that
+// the plaintext is ASCII before removing the padding, returning a
+// *HighASCIIError if it is not.
+func (o *IVKey) Decrypt(ciphertext []byte) ([]byte, error) {
+	plaintext, err := modes.DecryptCBC(o.block, o.key, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range plaintext {
+		if b >= 0x80 {
+			return nil, &HighASCIIError{Plaintext: plaintext}
+		}
+	}
+	return padding.Unpad(plaintext, aes.BlockSize)
+}
@@ -0,0 +1,50 @@
+package oracle
+
+import (
+	"crypto/subtle"
+	"errors"
+	"hash"
+	"io"
+)
+
+// ErrKeyLength is returned if the maximum key length is not positive
+var ErrKeyLength = errors.New("oracle: key length must be positive")
+
+// SecretPrefixMAC is the oracle of challenges 28 to 30. It authenticates
+// messages with the insecure MAC H(key || message), using a random key of
+// unknown length.
+type SecretPrefixMAC struct {
+	newHash func() hash.Hash
+	key     []byte
+}
+
+// NewSecretPrefixMAC creates a SecretPrefixMAC using the hash returned by
+// newHash. Its key is 1 to maxKeyLen bytes long, both length and content
+// read from random.
+func NewSecretPrefixMAC(random io.Reader, newHash func() hash.Hash, maxKeyLen int) (*SecretPrefixMAC, error) {
+	if maxKeyLen <= 0 {
+		return nil, ErrKeyLength
+	}
+	n, err := randomInt(random, 1, maxKeyLen)
+	if err != nil {
+		return nil, err
+	}
+	key, err := randomBytes(random, n)
+	if err != nil {
+		return nil, err
+	}
+	return &SecretPrefixMAC{newHash: newHash, key: key}, nil
+}
+
+// Sign returns the MAC of message
+func (o *SecretPrefixMAC) Sign(message []byte) []byte {
+	h := o.newHash()
+	h.Write(o.key)
+	h.Write(message)
+	return h.Sum(nil)
+}
+
+// Verify reports whether mac is the MAC of message
+func (o *SecretPrefixMAC) Verify(message, mac []byte) bool {
+	return subtle.ConstantTimeCompare(o.Sign(message), mac) == 1
+}
@@ -0,0 +1,129 @@
+// Package oracle provides encryption oracles for chosen-plaintext attacks,
+// and tools to probe them.
+package oracle
+
+import (
+	"crypto/aes"
+	"io"
+
+	"github.com/Xjs/cryptopals/modes"
+	"github.com/Xjs/cryptopals/padding"
+)
+
+// An Oracle encrypts attacker-chosen plaintext under a secret key
+type Oracle interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+}
+
+// An OracleFunc is an ordinary function used as an Oracle
+type OracleFunc func(plaintext []byte) ([]byte, error)
+
+// Encrypt returns f(plaintext)
+func (f OracleFunc) Encrypt(plaintext []byte) ([]byte, error) { return f(plaintext) }
+
+// A Mode is a block cipher mode of operation
+type Mode int
+
+// Modes an oracle may use
+const (
+	Unknown Mode = iota
+	ECB
+	CBC
+	CTR
+)
+
+func (m Mode) String() string {
+	switch m {
+	case ECB:
+		return "ECB"
+	case CBC:
+		return "CBC"
+	case CTR:
+		return "CTR"
+	}
+	return "unknown"
+}
+
+// randomBytes reads n bytes from r
+func randomBytes(r io.Reader, n int) ([]byte, error) {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, err
+	}
+	return b, nil
+}
+
+// randomInt returns a number from [min, max] read from r. It is slightly
+// biased, which does not matter for its use in oracles.
+func randomInt(r io.Reader, min, max int) (int, error) {
+	b, err := randomBytes(r, 2)
+	if err != nil {
+		return 0, err
+	}
+	return min + (int(b[0])<<8|int(b[1]))%(max-min+1), nil
+}
+
+// ECBOrCBC is the oracle of challenge 11. For every call to Encrypt, it picks a
+// random AES key, surrounds the plaintext with 5 to 10 random bytes on each side,
+// pads it with PKCS#7 and encrypts it either in ECB or in CBC mode with a random IV.
+type ECBOrCBC struct {
+	random io.Reader
+	// LastMode is the mode used by the most recent call to Encrypt
+	LastMode Mode
+}
+
+// NewECBOrCBC creates an ECBOrCBC oracle that draws all its randomness from
+// random. Pass crypto/rand.Reader, or a deterministic source for reproducible tests.
+func NewECBOrCBC(random io.Reader) *ECBOrCBC {
+	return &ECBOrCBC{random: random}
+}
+
+// Encrypt encrypts plaintext as described for ECBOrCBC
+func (o *ECBOrCBC) Encrypt(plaintext []byte) ([]byte, error) {
+	key, err := randomBytes(o.random, aes.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var input []byte
+	for i := 0; i < 2; i++ {
+		n, err := randomInt(o.random, 5, 10)
+		if err != nil {
+			return nil, err
+		}
+		junk, err := randomBytes(o.random, n)
+		if err != nil {
+			return nil, err
+		}
+		if i == 0 {
+			input = append(junk, plaintext...)
+		} else {
+			input = append(input, junk...)
+		}
+	}
+
+	padded, err := padding.Pad(input, aes.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+
+	coin, err := randomBytes(o.random, 1)
+	if err != nil {
+		return nil, err
+	}
+	if coin[0]&1 == 0 {
+		o.LastMode = ECB
+		return modes.EncryptECB(block, padded)
+	}
+
+	o.LastMode = CBC
+	iv, err := randomBytes(o.random, aes.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+	return modes.EncryptCBC(block, iv, padded)
+}
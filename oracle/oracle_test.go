@@ -0,0 +1,155 @@
+package oracle
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/sha256"
+	"math/rand"
+	"testing"
+)
+
+func TestECBOrCBCReproducible(t *testing.T) {
+	a := NewECBOrCBC(rand.New(rand.NewSource(7)))
+	b := NewECBOrCBC(rand.New(rand.NewSource(7)))
+	for i := 0; i < 10; i++ {
+		x, err := a.Encrypt([]byte("chosen plaintext"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		y, err := b.Encrypt([]byte("chosen plaintext"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(x, y) || a.LastMode != b.LastMode {
+			t.Fatalf("call %d: oracles with equal seeds differ", i)
+		}
+		if len(x)%aes.BlockSize != 0 || len(x) < 16+10 {
+			t.Errorf("call %d: unexpected ciphertext length %d", i, len(x))
+		}
+	}
+}
+
+func TestDetectMode(t *testing.T) {
+	o := NewECBOrCBC(rand.New(rand.NewSource(11)))
+	var seen [3]int
+	for i := 0; i < 200; i++ {
+		d, err := DetectMode(o, aes.BlockSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.Mode != o.LastMode {
+			t.Fatalf("call %d: DetectMode() = %v, oracle used %v", i, d.Mode, o.LastMode)
+		}
+		if d.Confidence != 1 {
+			t.Errorf("call %d: confidence %v, want 1", i, d.Confidence)
+		}
+		seen[d.Mode]++
+	}
+	if seen[ECB] == 0 || seen[CBC] == 0 {
+		t.Errorf("oracle did not use both modes: %v", seen)
+	}
+}
+
+func TestDetectModeOracleFunc(t *testing.T) {
+	identity := OracleFunc(func(p []byte) ([]byte, error) { return p, nil })
+	d, err := DetectMode(identity, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d.Mode != ECB {
+		t.Errorf("DetectMode(identity) = %v, want ECB", d.Mode)
+	}
+}
+
+func TestCTREdit(t *testing.T) {
+	o, err := NewCTREdit(rand.New(rand.NewSource(25)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ciphertext, err := o.Encrypt([]byte("hello, world"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	edited, err := o.Edit(ciphertext, 7, []byte("gophers!"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := o.Encrypt([]byte("hello, gophers!"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(edited, want) {
+		t.Errorf("Edit() = %x, want %x", edited, want)
+	}
+
+	if _, err := o.Edit(ciphertext, len(ciphertext)+1, []byte("x")); err != ErrEditRange {
+		t.Errorf("Edit() past the end: error = %v, want %v", err, ErrEditRange)
+	}
+}
+
+func TestIVKey(t *testing.T) {
+	o, err := NewIVKey(rand.New(rand.NewSource(27)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("plain old ASCII, two blocks long")
+	ciphertext, err := o.Encrypt(message)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := o.Decrypt(ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, message) {
+		t.Errorf("Decrypt() = %q, want %q", got, message)
+	}
+
+	ciphertext[0] ^= 0xff
+	if _, err := o.Decrypt(ciphertext); err == nil {
+		t.Fatal("Decrypt() of a tampered ciphertext succeeded")
+	} else if leak, ok := err.(*HighASCIIError); !ok || len(leak.Plaintext) != len(ciphertext) {
+		t.Errorf("Decrypt() of a tampered ciphertext: error = %v, want *HighASCIIError", err)
+	}
+}
+
+func TestComments(t *testing.T) {
+	for _, mode := range []Mode{CBC, CTR} {
+		o, err := NewComments(rand.New(rand.NewSource(16)), mode)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ciphertext, err := o.Encrypt([]byte(";admin=true;"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok, err := o.IsAdmin(ciphertext); err != nil || ok {
+			t.Errorf("%v: IsAdmin() of quoted input = %v, %v", mode, ok, err)
+		}
+	}
+	if _, err := NewComments(rand.New(rand.NewSource(16)), ECB); err != ErrUnsupportedMode {
+		t.Errorf("NewComments(ECB): error = %v, want %v", err, ErrUnsupportedMode)
+	}
+}
+
+func TestSecretPrefixMAC(t *testing.T) {
+	o, err := NewSecretPrefixMAC(rand.New(rand.NewSource(28)), sha256.New, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	message := []byte("comment1=cooking%20MCs")
+	mac := o.Sign(message)
+	if !o.Verify(message, mac) {
+		t.Error("Verify() rejected a valid MAC")
+	}
+	if o.Verify(append(message, '!'), mac) {
+		t.Error("Verify() accepted a MAC for another message")
+	}
+
+	for _, maxKeyLen := range []int{0, -1} {
+		if _, err := NewSecretPrefixMAC(rand.New(rand.NewSource(28)), sha256.New, maxKeyLen); err != ErrKeyLength {
+			t.Errorf("NewSecretPrefixMAC(%d) error = %v, want %v", maxKeyLen, err, ErrKeyLength)
+		}
+	}
+}
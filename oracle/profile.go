@@ -0,0 +1,53 @@
+package oracle
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"io"
+
+	"github.com/Xjs/cryptopals/kvcookie"
+	"github.com/Xjs/cryptopals/modes"
+	"github.com/Xjs/cryptopals/padding"
+)
+
+// Profile is the oracle of challenge 13. It encrypts the encoded
+// kvcookie.ProfileFor of the plaintext, taken as an email address,
+// with AES-128-ECB under a fixed random key.
+type Profile struct {
+	block cipher.Block
+}
+
+// NewProfile creates a Profile oracle with a key read from random
+func NewProfile(random io.Reader) (*Profile, error) {
+	key, err := randomBytes(random, aes.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return &Profile{block: block}, nil
+}
+
+// Encrypt encrypts the profile for the email address given as plaintext
+func (o *Profile) Encrypt(plaintext []byte) ([]byte, error) {
+	padded, err := padding.Pad([]byte(kvcookie.ProfileFor(string(plaintext)).Encode()), aes.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+	return modes.EncryptECB(o.block, padded)
+}
+
+// Decrypt decrypts and parses an encrypted profile
+func (o *Profile) Decrypt(ciphertext []byte) (kvcookie.Values, error) {
+	plaintext, err := modes.DecryptECB(o.block, ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err = padding.Unpad(plaintext, aes.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+	return kvcookie.Parse(string(plaintext))
+}
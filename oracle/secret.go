@@ -0,0 +1,61 @@
+package oracle
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"io"
+
+	"github.com/Xjs/cryptopals/modes"
+	"github.com/Xjs/cryptopals/padding"
+)
+
+// AppendSecret is the oracle of challenges 12 and 14. It encrypts
+// prefix || plaintext || secret with PKCS#7 padding in AES-128-ECB mode,
+// using a key and prefix that stay fixed for the lifetime of the oracle.
+type AppendSecret struct {
+	block  cipher.Block
+	prefix []byte
+	secret []byte
+}
+
+// NewAppendSecret creates an AppendSecret oracle hiding secret. It draws a
+// random key and a random prefix of 0 to maxPrefix bytes from random.
+// With maxPrefix 0, there is no prefix (challenge 12).
+func NewAppendSecret(random io.Reader, secret []byte, maxPrefix int) (*AppendSecret, error) {
+	key, err := randomBytes(random, aes.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	var prefix []byte
+	if maxPrefix > 0 {
+		n, err := randomInt(random, 0, maxPrefix)
+		if err != nil {
+			return nil, err
+		}
+		prefix, err = randomBytes(random, n)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &AppendSecret{block: block, prefix: prefix, secret: append([]byte(nil), secret...)}, nil
+}
+
+// Encrypt encrypts prefix || plaintext || secret
+func (o *AppendSecret) Encrypt(plaintext []byte) ([]byte, error) {
+	input := make([]byte, 0, len(o.prefix)+len(plaintext)+len(o.secret))
+	input = append(input, o.prefix...)
+	input = append(input, plaintext...)
+	input = append(input, o.secret...)
+
+	padded, err := padding.Pad(input, aes.BlockSize)
+	if err != nil {
+		return nil, err
+	}
+	return modes.EncryptECB(o.block, padded)
+}
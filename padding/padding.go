@@ -0,0 +1,176 @@
+// Package padding implements block cipher padding schemes: PKCS#7,
+// ANSI X.923 and ISO/IEC 7816-4.
+package padding
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"errors"
+)
+
+var (
+	// ErrInvalidBlockSize is returned for block sizes a scheme cannot handle
+	ErrInvalidBlockSize = errors.New("padding: invalid block size")
+	// ErrInvalidLength is returned when unpadding data that is empty or
+	// not a multiple of the block size
+	ErrInvalidLength = errors.New("padding: invalid length")
+	// ErrInvalidPadding is returned when unpadding data whose padding is malformed
+	ErrInvalidPadding = errors.New("padding: invalid padding")
+)
+
+// A Padder pads data to a multiple of a block size and removes that padding again.
+// Pad always adds at least one byte, so a full block is added to aligned data.
+type Padder interface {
+	Pad(data []byte, blockSize int) ([]byte, error)
+	Unpad(data []byte, blockSize int) ([]byte, error)
+}
+
+var (
+	// PKCS7 pads with n bytes of value n (RFC 5652, section 6.3)
+	PKCS7 Padder = pkcs7{}
+	// ANSIX923 pads with n-1 zero bytes followed by a byte of value n
+	ANSIX923 Padder = ansiX923{}
+	// ISO7816 pads with a single 0x80 byte followed by zero bytes (ISO/IEC 7816-4)
+	ISO7816 Padder = iso7816{}
+)
+
+// Pad pads data with PKCS#7
+func Pad(data []byte, blockSize int) ([]byte, error) { return PKCS7.Pad(data, blockSize) }
+
+// Unpad removes PKCS#7 padding from data
+func Unpad(data []byte, blockSize int) ([]byte, error) { return PKCS7.Unpad(data, blockSize) }
+
+// padLength returns the number of bytes needed to pad data of length n
+func padLength(n, blockSize int) int {
+	return blockSize - n%blockSize
+}
+
+// appendPadding returns a copy of data with the given padding appended
+func appendPadding(data, pad []byte) []byte {
+	result := make([]byte, len(data)+len(pad))
+	copy(result, data)
+	copy(result[len(data):], pad)
+	return result
+}
+
+func checkLength(data []byte, blockSize int) error {
+	if blockSize <= 0 {
+		return ErrInvalidBlockSize
+	}
+	if len(data) == 0 || len(data)%blockSize != 0 {
+		return ErrInvalidLength
+	}
+	return nil
+}
+
+type pkcs7 struct{}
+
+func (pkcs7) Pad(data []byte, blockSize int) ([]byte, error) {
+	if blockSize <= 0 || blockSize > 255 {
+		return nil, ErrInvalidBlockSize
+	}
+	n := padLength(len(data), blockSize)
+	return appendPadding(data, bytes.Repeat([]byte{byte(n)}, n)), nil
+}
+
+func (pkcs7) Unpad(data []byte, blockSize int) ([]byte, error) {
+	if err := checkLength(data, blockSize); err != nil {
+		return nil, err
+	}
+	n := int(data[len(data)-1])
+	if n == 0 || n > blockSize {
+		return nil, ErrInvalidPadding
+	}
+	for _, b := range data[len(data)-n:] {
+		if int(b) != n {
+			return nil, ErrInvalidPadding
+		}
+	}
+	return data[:len(data)-n], nil
+}
+
+// UnpadConstantTime removes PKCS#7 padding from data like Unpad, but the time
+// it takes depends only on the block size, not on the padding bytes. This
+// keeps a padding oracle from being turned into a timing oracle.
+func UnpadConstantTime(data []byte, blockSize int) ([]byte, error) {
+	if err := checkLength(data, blockSize); err != nil {
+		return nil, err
+	}
+	if blockSize > 255 {
+		return nil, ErrInvalidBlockSize
+	}
+
+	last := data[len(data)-blockSize:]
+	n := int(last[blockSize-1])
+
+	// good is 1 as long as the padding is valid
+	good := 1 - subtle.ConstantTimeByteEq(byte(n), 0)
+	good &= subtle.ConstantTimeLessOrEq(n, blockSize)
+	for i := 0; i < blockSize; i++ {
+		// inPadding is 1 for the last n bytes of the block
+		inPadding := subtle.ConstantTimeLessOrEq(blockSize-i, n)
+		matches := subtle.ConstantTimeByteEq(last[i], byte(n))
+		good &= subtle.ConstantTimeSelect(inPadding, matches, 1)
+	}
+
+	if good != 1 {
+		return nil, ErrInvalidPadding
+	}
+	return data[:len(data)-n], nil
+}
+
+type ansiX923 struct{}
+
+func (ansiX923) Pad(data []byte, blockSize int) ([]byte, error) {
+	if blockSize <= 0 || blockSize > 255 {
+		return nil, ErrInvalidBlockSize
+	}
+	n := padLength(len(data), blockSize)
+	pad := make([]byte, n)
+	pad[n-1] = byte(n)
+	return appendPadding(data, pad), nil
+}
+
+func (ansiX923) Unpad(data []byte, blockSize int) ([]byte, error) {
+	if err := checkLength(data, blockSize); err != nil {
+		return nil, err
+	}
+	n := int(data[len(data)-1])
+	if n == 0 || n > blockSize {
+		return nil, ErrInvalidPadding
+	}
+	for _, b := range data[len(data)-n : len(data)-1] {
+		if b != 0 {
+			return nil, ErrInvalidPadding
+		}
+	}
+	return data[:len(data)-n], nil
+}
+
+type iso7816 struct{}
+
+func (iso7816) Pad(data []byte, blockSize int) ([]byte, error) {
+	if blockSize <= 0 {
+		return nil, ErrInvalidBlockSize
+	}
+	pad := make([]byte, padLength(len(data), blockSize))
+	pad[0] = 0x80
+	return appendPadding(data, pad), nil
+}
+
+func (iso7816) Unpad(data []byte, blockSize int) ([]byte, error) {
+	if err := checkLength(data, blockSize); err != nil {
+		return nil, err
+	}
+	for i := len(data) - 1; i >= len(data)-blockSize; i-- {
+		switch data[i] {
+		case 0x80:
+			return data[:i], nil
+		case 0:
+			continue
+		default:
+			return nil, ErrInvalidPadding
+		}
+	}
+	return nil, ErrInvalidPadding
+}
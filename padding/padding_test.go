@@ -0,0 +1,93 @@
+package padding
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPad(t *testing.T) {
+	tests := []struct {
+		name      string
+		padder    Padder
+		data      string
+		blockSize int
+		want      string
+	}{
+		{"pkcs7-ex9", PKCS7, "YELLOW SUBMARINE", 20, "YELLOW SUBMARINE\x04\x04\x04\x04"},
+		{"pkcs7-full-block", PKCS7, "YELLOW SUBMARINE", 16, "YELLOW SUBMARINE" + string(bytes.Repeat([]byte{16}, 16))},
+		{"pkcs7-empty", PKCS7, "", 4, "\x04\x04\x04\x04"},
+		{"x923", ANSIX923, "YELLOW SUBMARINE", 20, "YELLOW SUBMARINE\x00\x00\x00\x04"},
+		{"x923-one", ANSIX923, "abc", 4, "abc\x01"},
+		{"iso7816", ISO7816, "YELLOW SUBMARINE", 20, "YELLOW SUBMARINE\x80\x00\x00\x00"},
+		{"iso7816-one", ISO7816, "abc", 4, "abc\x80"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.padder.Pad([]byte(tt.data), tt.blockSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Pad() = %q, want %q", got, tt.want)
+			}
+			unpadded, err := tt.padder.Unpad(got, tt.blockSize)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(unpadded) != tt.data {
+				t.Errorf("Unpad() = %q, want %q", unpadded, tt.data)
+			}
+		})
+	}
+}
+
+func TestUnpadErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		padder    Padder
+		data      string
+		blockSize int
+		want      error
+	}{
+		{"pkcs7-valid", PKCS7, "ICE ICE BABY\x04\x04\x04\x04", 16, nil},
+		{"pkcs7-wrong-bytes", PKCS7, "ICE ICE BABY\x05\x05\x05\x05", 16, ErrInvalidPadding},
+		{"pkcs7-mixed", PKCS7, "ICE ICE BABY\x01\x02\x03\x04", 16, ErrInvalidPadding},
+		{"pkcs7-zero", PKCS7, "ICE ICE BABY\x00\x00\x00\x00", 16, ErrInvalidPadding},
+		{"pkcs7-too-large", PKCS7, "ICE ICE BABY\x11\x11\x11\x11", 16, ErrInvalidPadding},
+		{"pkcs7-unaligned", PKCS7, "ICE ICE BABY\x04\x04\x04", 16, ErrInvalidLength},
+		{"pkcs7-empty", PKCS7, "", 16, ErrInvalidLength},
+		{"pkcs7-block-size", PKCS7, "\x01", 0, ErrInvalidBlockSize},
+		{"x923-nonzero", ANSIX923, "abcd\x00\x01\x00\x04", 8, ErrInvalidPadding},
+		{"iso7816-no-marker", ISO7816, "abcd\x00\x00\x00\x00", 8, ErrInvalidPadding},
+		{"iso7816-garbage", ISO7816, "abcd\x80\x00\x01\x00", 8, ErrInvalidPadding},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.padder.Unpad([]byte(tt.data), tt.blockSize); err != tt.want {
+				t.Errorf("Unpad() error = %v, want %v", err, tt.want)
+			}
+			if tt.padder == PKCS7 {
+				if _, err := UnpadConstantTime([]byte(tt.data), tt.blockSize); err != tt.want {
+					t.Errorf("UnpadConstantTime() error = %v, want %v", err, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestUnpadConstantTimeMatchesUnpad(t *testing.T) {
+	block := make([]byte, 16)
+	for last := 0; last < 256; last++ {
+		for fill := 0; fill < 256; fill += 15 {
+			for i := range block {
+				block[i] = byte(fill)
+			}
+			block[15] = byte(last)
+			want, wantErr := Unpad(block, 16)
+			got, err := UnpadConstantTime(block, 16)
+			if err != wantErr || !bytes.Equal(got, want) {
+				t.Fatalf("block %x: UnpadConstantTime() = %x, %v; Unpad() = %x, %v", block, got, err, want, wantErr)
+			}
+		}
+	}
+}
@@ -36,9 +36,12 @@ func NewByteScoreHistogram(m map[byte]Score) ByteScoreHistogram {
 	return result
 }
 
-// GetHigh gets the index-th highest entry from the histogram
+// GetHigh gets the index-th highest entry from the histogram. The histogram is
+// sorted first unless it already is, so the order of equal scores is kept.
 func (bh ByteScoreHistogram) GetHigh(index int) ByteScoreHistogramEntry {
-	sort.Sort(bh)
+	if !sort.IsSorted(bh) {
+		sort.Sort(bh)
+	}
 	return bh[len(bh)-1-index]
 }
 
@@ -93,6 +96,46 @@ func (bh SizeScoreHistogram) String() string {
 	return result
 }
 
+// A StringScoreHistogramEntry is a single entry of a StringScoreHistogram
+type StringScoreHistogramEntry struct {
+	String string
+	Score  Score
+}
+
+// A StringScoreHistogram is a map between strings and scores. It can be sorted.
+type StringScoreHistogram []StringScoreHistogramEntry
+
+func (sh StringScoreHistogram) Less(a, b int) bool { return sh[a].Score < sh[b].Score }
+func (sh StringScoreHistogram) Swap(a, b int)      { sh[a], sh[b] = sh[b], sh[a] }
+func (sh StringScoreHistogram) Len() int           { return len(sh) }
+
+// NewStringScoreHistogram creates a sorted StringScoreHistogram from a map string -> Score
+func NewStringScoreHistogram(m map[string]Score) StringScoreHistogram {
+	var result StringScoreHistogram
+	for s, score := range m {
+		result = append(result, StringScoreHistogramEntry{String: s, Score: score})
+	}
+	sort.Sort(result)
+	return result
+}
+
+// GetHigh gets the index-th highest entry from the histogram
+func (sh StringScoreHistogram) GetHigh(index int) StringScoreHistogramEntry {
+	sort.Sort(sh)
+	return sh[len(sh)-1-index]
+}
+
+func (sh StringScoreHistogram) String() string {
+	var result string
+	for i, entry := range sh {
+		result += fmt.Sprintf("%s: %.2f", entry.String, entry.Score)
+		if i != len(sh)-1 {
+			result += ", "
+		}
+	}
+	return result
+}
+
 // A ByteHistogramEntry is a single entry of a ByteHistogram
 type ByteHistogramEntry struct {
 	Byte  byte
@@ -133,6 +176,46 @@ func (bh ByteHistogram) String() string {
 	return result
 }
 
+// A BlockHistogramEntry is a single entry of a BlockHistogram
+type BlockHistogramEntry struct {
+	Block []byte
+	Count int
+}
+
+// A BlockHistogram is a map between blocks of bytes and counts. It can be sorted.
+type BlockHistogram []BlockHistogramEntry
+
+func (bh BlockHistogram) Less(a, b int) bool { return bh[a].Count < bh[b].Count }
+func (bh BlockHistogram) Swap(a, b int)      { bh[a], bh[b] = bh[b], bh[a] }
+func (bh BlockHistogram) Len() int           { return len(bh) }
+
+// NewBlockHistogram creates a sorted BlockHistogram from a map block -> int
+func NewBlockHistogram(m map[string]int) BlockHistogram {
+	var result BlockHistogram
+	for b, count := range m {
+		result = append(result, BlockHistogramEntry{Block: []byte(b), Count: count})
+	}
+	sort.Sort(result)
+	return result
+}
+
+// GetHigh gets the index-th highest entry from the histogram
+func (bh BlockHistogram) GetHigh(index int) BlockHistogramEntry {
+	sort.Sort(bh)
+	return bh[len(bh)-1-index]
+}
+
+func (bh BlockHistogram) String() string {
+	var result string
+	for i, entry := range bh {
+		result += fmt.Sprintf("%x: %d", entry.Block, entry.Count)
+		if i != len(bh)-1 {
+			result += ", "
+		}
+	}
+	return result
+}
+
 // NewByteHistogramFromRunes creates a ByteHistogram by converting runes to bytes
 func NewByteHistogramFromRunes(rh map[rune]int) ByteHistogram {
 	var bh ByteHistogram
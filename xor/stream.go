@@ -0,0 +1,46 @@
+package xor
+
+import (
+	"crypto/cipher"
+	"errors"
+)
+
+// ErrEmptyKey is returned when a key of length zero is used
+var ErrEmptyKey = errors.New("xor: empty key")
+
+type repeatingKeyStream struct {
+	key    []byte
+	offset int
+}
+
+// NewRepeatingKeyStream returns a cipher.Stream that XORs its input with
+// the given key, repeating it as often as needed. The position within the
+// key is kept across calls to XORKeyStream, so encrypting a message in
+// chunks yields the same result as calling Encrypt on the whole message.
+// The stream can be used with cipher.StreamReader and cipher.StreamWriter.
+func NewRepeatingKeyStream(key []byte) (cipher.Stream, error) {
+	if len(key) == 0 {
+		return nil, ErrEmptyKey
+	}
+
+	k := make([]byte, len(key))
+	copy(k, key)
+
+	return &repeatingKeyStream{key: k}, nil
+}
+
+// XORKeyStream XORs each byte in src with the next byte of the key
+// and writes the result to dst. It panics if dst is shorter than src.
+func (s *repeatingKeyStream) XORKeyStream(dst, src []byte) {
+	if len(dst) < len(src) {
+		panic("xor: output smaller than input")
+	}
+
+	for i, inputByte := range src {
+		dst[i] = inputByte ^ s.key[s.offset]
+		s.offset++
+		if s.offset == len(s.key) {
+			s.offset = 0
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package xor
+
+import (
+	"bytes"
+	"crypto/cipher"
+	"io"
+	"io/ioutil"
+	"testing"
+)
+
+func TestNewRepeatingKeyStream(t *testing.T) {
+	if _, err := NewRepeatingKeyStream(nil); err != ErrEmptyKey {
+		t.Errorf("NewRepeatingKeyStream(nil) error = %v, want %v", err, ErrEmptyKey)
+	}
+	if _, err := NewRepeatingKeyStream([]byte{}); err != ErrEmptyKey {
+		t.Errorf("NewRepeatingKeyStream([]byte{}) error = %v, want %v", err, ErrEmptyKey)
+	}
+}
+
+func TestRepeatingKeyStreamChunks(t *testing.T) {
+	input := []byte("Burning 'em, if you ain't quick and nimble\nI go crazy when I hear a cymbal")
+	key := []byte("ICE")
+	want := Encrypt(input, key)
+
+	for _, chunk := range []int{1, 2, 3, 4, 7, 16, len(input)} {
+		stream, err := NewRepeatingKeyStream(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got := make([]byte, len(input))
+		for i := 0; i < len(input); i += chunk {
+			end := i + chunk
+			if end > len(input) {
+				end = len(input)
+			}
+			stream.XORKeyStream(got[i:end], input[i:end])
+		}
+
+		if !bytes.Equal(got, want) {
+			t.Errorf("chunk size %d: got %x, want %x", chunk, got, want)
+		}
+	}
+}
+
+func TestRepeatingKeyStreamReaderWriter(t *testing.T) {
+	input := bytes.Repeat([]byte("Streaming plaintext, "), 1000)
+	key := []byte("secret key")
+	want := Encrypt(input, key)
+
+	stream, err := NewRepeatingKeyStream(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	w := cipher.StreamWriter{S: stream, W: &buf}
+	if _, err := io.Copy(w, bytes.NewReader(input)); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(buf.Bytes(), want) {
+		t.Error("StreamWriter output differs from Encrypt")
+	}
+
+	stream, err = NewRepeatingKeyStream(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := cipher.StreamReader{S: stream, R: bytes.NewReader(want)}
+	got, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, input) {
+		t.Error("StreamReader did not restore the plaintext")
+	}
+}